@@ -5,17 +5,29 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"log"
+	"path/filepath"
 	"strings"
-	"time"
+	"sync"
+	"text/template/parse"
+
+	"example/server"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/bytebufferpool"
 )
 
 type MyViews struct {
+	mutex     sync.RWMutex
 	templates map[string]*template.Template
 }
 
+var templateFuncs = template.FuncMap{
+	"asset":  server.Asset,
+	"nav":    server.NavItems,
+	"crumbs": server.Breadcrumbs,
+	"url":    server.RouteURL,
+}
+
 func reverse(numbers []string) []string {
 	for i := 0; i < len(numbers)/2; i++ {
 		j := len(numbers) - i - 1
@@ -25,18 +37,19 @@ func reverse(numbers []string) []string {
 }
 
 func (v *MyViews) Load() error {
-	v.templates = make(map[string]*template.Template)
-
-	parsePage := func(templates ...string) error {
-		name := templates[0]
-		templates = append(templates, "MainLayout", "NavMenu", "_Layout")
-		templates = reverse(templates)
-		for i := 0; i < len(templates); i++ {
-			templates[i] = fmt.Sprintf("templates/%s.html", templates[i])
+	templates := make(map[string]*template.Template)
+
+	parsePage := func(files ...string) error {
+		name := files[0]
+		files = append(files, "MainLayout", "NavMenu", "_Layout")
+		files = reverse(files)
+		for i := 0; i < len(files); i++ {
+			files[i] = fmt.Sprintf("templates/%s.html", files[i])
 		}
-		tmpl, error := template.ParseFiles(templates...)
+		tmpl, error := template.New(filepath.Base(files[0])).
+			Funcs(templateFuncs).ParseFiles(files...)
 		if tmpl != nil {
-			v.templates[name] = tmpl
+			templates[name] = tmpl
 			return nil
 		} else {
 			return error
@@ -63,27 +76,159 @@ func (v *MyViews) Load() error {
 		return error
 	}
 
-	tmpl, error := template.ParseFiles("templates/Forecasts.html")
+	error = parsePage("Login")
+	if error != nil {
+		return error
+	}
+
+	error = parsePage("Register")
+	if error != nil {
+		return error
+	}
+
+	error = parsePage("Users")
+	if error != nil {
+		return error
+	}
+
+	error = parsePage("Admin")
+	if error != nil {
+		return error
+	}
+
+	error = parsePage("Error")
+	if error != nil {
+		return error
+	}
+
+	tmpl, error := template.New("Forecasts.html").
+		Funcs(templateFuncs).ParseFiles("templates/Forecasts.html")
 	if error != nil {
 		return error
 	} else {
-		v.templates["Forecasts"] = tmpl
+		templates["Forecasts"] = tmpl
 	}
 
+	tmpl, error = template.New("TooManyRequests.html").
+		Funcs(templateFuncs).ParseFiles("templates/TooManyRequests.html")
+	if error != nil {
+		return error
+	} else {
+		templates["TooManyRequests"] = tmpl
+	}
+
+	for name, tmpl := range templates {
+		if err := checkRouteURLs(tmpl); err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+		// Every page defines the block the layout renders.
+		if tmpl.Lookup("_Layout.html") != nil &&
+			tmpl.Lookup("main-article") == nil {
+			return fmt.Errorf("template %s doesn't define main-article", name)
+		}
+	}
+
+	v.mutex.Lock()
+	v.templates = templates
+	v.mutex.Unlock()
 	return nil
 }
 
+// Checks that the template's url calls name routes the table has, and
+// only pass them parameters they take, so a typo fails loading the
+// templates rather than rendering a page.
+func checkRouteURLs(tmpl *template.Template) error {
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := checkURLCalls(t.Tree.Root); err != nil {
+			return fmt.Errorf("%s: %w", t.Name(), err)
+		}
+	}
+	return nil
+}
+
+func checkURLCalls(node parse.Node) error {
+	var children []parse.Node
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			children = n.Nodes
+		}
+	case *parse.ActionNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.IfNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.RangeNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.WithNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.TemplateNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.PipeNode:
+		if n != nil {
+			for _, cmd := range n.Cmds {
+				children = append(children, cmd)
+			}
+		}
+	case *parse.CommandNode:
+		if err := checkURLCall(n.Args); err != nil {
+			return err
+		}
+		children = n.Args
+	}
+	for _, child := range children {
+		if err := checkURLCalls(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks a call like url "login" "next" .Next by building a URL from it,
+// with its values left out.  Calls whose names aren't constants can only
+// be checked as they run.
+func checkURLCall(args []parse.Node) error {
+	if len(args) < 2 {
+		return nil
+	}
+	if id, ok := args[0].(*parse.IdentifierNode); !ok || id.Ident != "url" {
+		return nil
+	}
+	name, ok := args[1].(*parse.StringNode)
+	if !ok {
+		return nil
+	}
+	var params []any
+	for i, arg := range args[2:] {
+		if i%2 == 1 {
+			params = append(params, "")
+		} else if key, ok := arg.(*parse.StringNode); ok {
+			params = append(params, key.Text)
+		} else {
+			params = nil
+			break
+		}
+	}
+	_, err := server.RouteURL(server.RouteName(name.Text), params...)
+	return err
+}
+
 func (v *MyViews) Render(w io.Writer, templateName string,
 	data interface{}, _ignored ...string) error {
+	v.mutex.RLock()
+	templates := v.templates
+	v.mutex.RUnlock()
 	tmpls := strings.Split(templateName, " ")
 	if len(tmpls) == 1 {
-		tmpl := v.templates[templateName]
+		tmpl := templates[templateName]
 		if tmpl == nil {
 			return errors.New(fmt.Sprintf("No template named %s", templateName))
 		}
 		return tmpl.Execute(w, data)
 	} else if len(tmpls) == 2 {
-		tmpl := v.templates[tmpls[0]]
+		tmpl := templates[tmpls[0]]
 		if tmpl == nil {
 			return errors.New(fmt.Sprintf("No template named %s", tmpls[0]))
 		}
@@ -100,45 +245,117 @@ func dataFromContext(c *fiber.Ctx) fiber.Map {
 		cmap["HxBoosted"] = true
 	}
 	cmap["Path"] = c.Route().Path
+	cmap["Title"] = server.RouteTitle(c)
+	cmap["Nonce"] = server.CSPNonce(c.UserContext())
+	cmap["HtmxConfig"] = server.HTMXConfig(c.UserContext())
+	cmap["Session"] = server.CurrentSession(c)
+	cmap["User"] = server.CurrentSession(c).Username()
+	cmap["Access"] = server.CurrentAccess(c)
 	return cmap
 }
 
-func main() {
-	app := fiber.New(fiber.Config{
-		Views: new(MyViews),
-	})
+// Renders a template inside a span, so template execution shows up in
+// traces separately from the handler that asked for it.
+func (v *MyViews) render(c *fiber.Ctx, name string, data interface{}) error {
+	_, span := server.DefaultTracer.Start(c.UserContext(), "MyViews.Render")
+	defer span.End()
+	span.SetAttribute("template", name)
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	if err := v.Render(buf, name, data); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Context().SetBody(buf.Bytes())
+	return nil
+}
 
-	app.Static("/", "./wwwroot")
+// Renders a page with the data every page has, and data.
+func (v *MyViews) page(c *fiber.Ctx, name string, data fiber.Map) error {
+	c.Vary("HX-Boosted")
+	cmap := dataFromContext(c)
+	for key, value := range data {
+		cmap[key] = value
+	}
+	return v.render(c, name, cmap)
+}
 
-	app.Get("/", func(c *fiber.Ctx) error {
-		c.Set("Vary", "HX-Boosted")
-		return c.Render("Index", dataFromContext(c))
-	})
-	app.Get("/about", func(c *fiber.Ctx) error {
-		c.Set("Vary", "HX-Boosted")
-		return c.Render("About", dataFromContext(c))
-	})
-	app.Get("/counter", func(c *fiber.Ctx) error {
-		c.Set("Vary", "HX-Boosted")
-		cmap := dataFromContext(c)
-		cmap["CurrentCount"] = 0
-		cmap["NextCount"] = 1
-		return c.Render("Counter", cmap)
-	})
-	app.Get("/increment", func(c *fiber.Ctx) error {
-		count := c.QueryInt("count", 0)
-		return c.Render("Counter main-article", fiber.Map{
-			"CurrentCount": count,
-			"NextCount":    count + 1,
-		})
+func (v *MyViews) Index(c *fiber.Ctx) error {
+	return v.page(c, "Index", nil)
+}
+
+func (v *MyViews) About(c *fiber.Ctx) error {
+	return v.page(c, "About", nil)
+}
+
+func (v *MyViews) Counter(c *fiber.Ctx, count int) error {
+	return v.page(c, "Counter", fiber.Map{
+		"CurrentCount": count,
+		"NextCount":    count + 1,
 	})
-	app.Get("/fetchdata", func(c *fiber.Ctx) error {
-		c.Set("Vary", "HX-Boosted")
-		return c.Render("FetchData", dataFromContext(c))
+}
+
+func (v *MyViews) CounterForm(c *fiber.Ctx, count int) error {
+	return v.render(c, "Counter main-article", fiber.Map{
+		"CurrentCount": count,
+		"NextCount":    count + 1,
 	})
-	app.Post("/forecasts", func(c *fiber.Ctx) error {
-		return c.Render("Forecasts", getForecasts(time.Now()))
+}
+
+func (v *MyViews) FetchData(c *fiber.Ctx) error {
+	return v.page(c, "FetchData", nil)
+}
+
+func (v *MyViews) Forecasts(c *fiber.Ctx, forecasts []server.Forecast) error {
+	return v.render(c, "Forecasts", forecasts)
+}
+
+func (v *MyViews) Login(c *fiber.Ctx, form server.AccountForm) error {
+	return v.page(c, "Login", fiber.Map{"Form": form})
+}
+
+func (v *MyViews) Register(c *fiber.Ctx, form server.AccountForm) error {
+	return v.page(c, "Register", fiber.Map{"Form": form})
+}
+
+func (v *MyViews) Admin(c *fiber.Ctx, pages []server.Route) error {
+	return v.page(c, "Admin", fiber.Map{"Pages": pages})
+}
+
+func (v *MyViews) Users(c *fiber.Ctx, users []*server.User) error {
+	return v.page(c, "Users", fiber.Map{"Users": users})
+}
+
+// Error pages are for whatever path was asked for, which may have no
+// route, and are titled by their status.
+func (v *MyViews) Error(c *fiber.Ctx, info server.ErrorInfo,
+	fragment bool) error {
+	data := fiber.Map{
+		"Path":      c.Path(),
+		"Status":    info.Status,
+		"Title":     info.Title,
+		"Message":   info.Message,
+		"Detail":    info.Detail,
+		"RequestID": info.RequestID,
+	}
+	if fragment {
+		return v.render(c, "Error main-article", data)
+	}
+	return v.page(c, "Error", data)
+}
+
+func (v *MyViews) TooManyRequests(c *fiber.Ctx, method, url string,
+	retryAfter int) error {
+	return v.render(c, "TooManyRequests", fiber.Map{
+		"Method":     method,
+		"URL":        url,
+		"RetryAfter": retryAfter,
 	})
+}
 
-	log.Fatal(app.Listen(":3000"))
+var frontend = &server.Frontend{Views: new(MyViews)}
+
+func main() {
+	frontend.Main()
 }
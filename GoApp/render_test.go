@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example/server"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Benchmarks of rendering every page and fragment, without HTTP in the
+// way.  GoTemplApp has the same benchmarks, under the same names, for
+// RenderPage and RenderC.
+
+var benchmarkDate = time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+var benchmarkUsers = []*server.User{
+	{Name: "ada", Roles: []string{server.RoleMember, server.RoleAdmin}},
+	{Name: "grace", Roles: []string{server.RoleMember}},
+	{Name: "linus", Roles: []string{server.RoleMember}},
+}
+
+// The pages under /admin that benchmarkUsers[0] may see.
+var benchmarkAdminPages = []server.Route{
+	{Path: "/admin/cache", Title: "Page cache"},
+	{Path: "/admin/users", Title: "Users"},
+}
+
+// A request for path as a logged in admin, who sees every nav item.
+func benchmarkCtx(b *testing.B, path string, boosted bool) *fiber.Ctx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	if boosted {
+		ctx.Request.Header.Set("HX-Request", "true")
+		ctx.Request.Header.Set("HX-Boosted", "true")
+	}
+	app := fiber.New()
+	c := app.AcquireCtx(ctx)
+	b.Cleanup(func() { app.ReleaseCtx(c) })
+	nonce := "r4nd0mN0nceF0rBenchm4=="
+	c.Locals("user", benchmarkUsers[0])
+	c.Locals("cspNonce", nonce)
+	c.SetUserContext(server.WithCSPNonce(context.Background(), nonce))
+	return c
+}
+
+func benchmarkViews(b *testing.B) *MyViews {
+	views := new(MyViews)
+	if err := views.Load(); err != nil {
+		b.Fatal(err)
+	}
+	return views
+}
+
+type benchmarkPage struct {
+	name string
+	path string
+	data fiber.Map
+}
+
+func benchmarkPages() []benchmarkPage {
+	return []benchmarkPage{
+		{"Index", "/", nil},
+		{"About", "/about", nil},
+		{"Counter", "/counter", fiber.Map{"CurrentCount": 42, "NextCount": 43}},
+		{"FetchData", "/fetchdata", nil},
+		{"Login", "/login", fiber.Map{"Form": server.AccountForm{Next: "/counter"}}},
+		{"Register", "/register", fiber.Map{"Form": server.AccountForm{}}},
+		{"Admin", "/admin", fiber.Map{"Pages": benchmarkAdminPages}},
+		{"Users", "/admin/users", fiber.Map{"Users": benchmarkUsers}},
+		{"Error", "/missing", benchmarkError},
+	}
+}
+
+var benchmarkError = fiber.Map{
+	"Status":    fiber.StatusNotFound,
+	"Title":     "Not Found",
+	"Message":   "Sorry, there's nothing at this address.",
+	"RequestID": "0b5e6a0e-6b1e-4d6c-9a43-2b1f0d1c3e55",
+}
+
+func BenchmarkPage(b *testing.B) {
+	views := benchmarkViews(b)
+	for _, page := range benchmarkPages() {
+		for _, boosted := range []bool{false, true} {
+			name := page.name
+			if boosted {
+				name += "/boosted"
+			}
+			b.Run(name, func(b *testing.B) {
+				c := benchmarkCtx(b, page.path, boosted)
+				data := dataFromContext(c)
+				for key, value := range page.data {
+					data[key] = value
+				}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := views.render(c, page.name, data); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.SetBytes(int64(len(c.Response().Body())))
+			})
+		}
+	}
+}
+
+func BenchmarkFragment(b *testing.B) {
+	views := benchmarkViews(b)
+	fragments := []struct {
+		name     string
+		template string
+		data     interface{}
+	}{
+		{"Counter", "Counter main-article",
+			fiber.Map{"CurrentCount": 42, "NextCount": 43}},
+		{"Forecasts", "Forecasts",
+			server.GetForecasts(context.Background(), benchmarkDate)},
+		{"TooManyRequests", "TooManyRequests", fiber.Map{
+			"Method":     fiber.MethodPost,
+			"URL":        "/forecasts",
+			"RetryAfter": 3,
+		}},
+		{"Error", "Error main-article", benchmarkError},
+	}
+	for _, fragment := range fragments {
+		b.Run(fragment.name, func(b *testing.B) {
+			c := benchmarkCtx(b, "/", false)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := views.render(c, fragment.template,
+					fragment.data); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.SetBytes(int64(len(c.Response().Body())))
+		})
+	}
+}
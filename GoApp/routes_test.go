@@ -0,0 +1,44 @@
+package main
+
+import (
+	"html/template"
+	"testing"
+
+	"example/server/servertest"
+)
+
+// The tests every app runs, against these views.  Run go test -update to
+// rewrite the golden files after changing a page on purpose, then review
+// the diff.
+
+func TestGoldenRoutes(t *testing.T) {
+	servertest.GoldenRoutes(t, frontend)
+}
+
+func TestAccessibility(t *testing.T) {
+	servertest.Accessibility(t, frontend)
+}
+
+func TestRegisterAndCount(t *testing.T) {
+	servertest.RegisterAndCount(t, frontend)
+}
+
+func TestBoostedNavigation(t *testing.T) {
+	servertest.BoostedNavigation(t, frontend)
+}
+
+func TestCheckRouteURLs(t *testing.T) {
+	for text, ok := range map[string]bool{
+		`<a href="{{url "login" "next" .Next}}">`:           true,
+		`{{if .}}<a href="{{url .Name}}">{{end}}`:           true,
+		`{{define "x"}}<a href="{{url "nowhere"}}">{{end}}`: false,
+		`{{range .}}{{url "login" "count" 1}}{{end}}`:       false,
+		`{{with .}}{{url "login" "next"}}{{end}}`:           false,
+	} {
+		tmpl := template.Must(template.New("t").Funcs(templateFuncs).
+			Parse(text))
+		if err := checkRouteURLs(tmpl); (err == nil) != ok {
+			t.Errorf("%s: got %v", text, err)
+		}
+	}
+}
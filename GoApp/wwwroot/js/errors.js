@@ -0,0 +1,11 @@
+// htmx drops 4xx and 5xx responses by default.  The server retargets its
+// error fragments with HX-Retarget, so swap those in instead, along with
+// the 429 fragments that retry rate limited requests.
+document.body.addEventListener("htmx:beforeSwap", function (evt) {
+    var xhr = evt.detail.xhr;
+    if (xhr.status === 429 ||
+        (xhr.status >= 400 && xhr.getResponseHeader("HX-Retarget"))) {
+        evt.detail.shouldSwap = true;
+        evt.detail.isError = false;
+    }
+});
@@ -0,0 +1,46 @@
+#!/usr/bin/bash
+#
+# Runs the render benchmarks of GoApp and GoTemplApp at two commits and
+# compares them, failing if rendering got slower.
+#
+#   ./bench-commits.sh [old-commit] [new-commit]
+#
+# The commits default to HEAD~1 and the working tree.  Extra benchmark
+# flags can be passed in BENCHFLAGS.
+
+set -e
+old=${1:-HEAD~1}
+new=$2
+here=$(cd "$(dirname "$0")" && pwd)
+root=$(git -C "$here" rev-parse --show-toplevel)
+out=$(mktemp -d)
+flags=${BENCHFLAGS:--count 5}
+
+bench() {
+    local tree=$1 label=$2
+    for app in GoApp GoTemplApp; do
+        (cd "$tree/$app" && go test -run NONE -bench . $flags) \
+            > "$out/$app-$label.txt"
+    done
+}
+
+git -C "$root" worktree add --detach "$out/old" "$old" > /dev/null
+trap 'git -C "$root" worktree remove --force "$out/old"' EXIT
+bench "$out/old" old
+if [ -n "$new" ]; then
+    git -C "$root" worktree add --detach "$out/new" "$new" > /dev/null
+    trap 'git -C "$root" worktree remove --force "$out/old"; git -C "$root" worktree remove --force "$out/new"' EXIT
+    bench "$out/new" new
+else
+    bench "$root" new
+fi
+
+status=0
+for app in GoApp GoTemplApp; do
+    echo "## $app"
+    echo
+    (cd "$here" && go run ./benchdiff "$out/$app-old.txt" "$out/$app-new.txt") \
+        || status=1
+    echo
+done
+exit $status
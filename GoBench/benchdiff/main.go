@@ -0,0 +1,148 @@
+// Benchdiff compares two sets of go test -bench results, such as the same
+// app's render benchmarks at two commits, and fails when a benchmark got
+// slower or allocates more by more than a threshold.
+//
+//	go test -run NONE -bench . -count 5 > old.txt
+//	(change things)
+//	go test -run NONE -bench . -count 5 > new.txt
+//	go run ./benchdiff old.txt new.txt
+//
+// GoApp and GoTemplApp name their render benchmarks alike, so their
+// results can be compared with each other too.  bench-commits.sh runs
+// the benchmarks at two commits and compares them.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The averages of a benchmark's runs, by unit, like "ns/op".
+type measurements map[string]float64
+
+// Units that are better when smaller, and are checked for regressions.
+var units = []string{"ns/op", "B/op", "allocs/op"}
+
+// Like "BenchmarkPage/About-8  2000  32869 ns/op  72.89 MB/s".
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+func parseResults(path string) (map[string]measurements, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sums := make(map[string]measurements)
+	counts := make(map[string]map[string]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if sums[name] == nil {
+			sums[name] = make(measurements)
+			counts[name] = make(map[string]int)
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			sums[name][fields[i+1]] += value
+			counts[name][fields[i+1]]++
+		}
+	}
+	for name, sum := range sums {
+		for unit := range sum {
+			sum[unit] /= float64(counts[name][unit])
+		}
+	}
+	return sums, scanner.Err()
+}
+
+func delta(before, after float64) float64 {
+	if before == 0 {
+		return 0
+	}
+	return (after - before) / before * 100
+}
+
+func main() {
+	threshold := flag.Float64("threshold", 10,
+		"percent a benchmark may get worse by before it's a regression")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"usage: benchdiff [flags] old.txt new.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	before, err := parseResults(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	after, err := parseResults(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var names []string
+	for name := range after {
+		if before[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Print("| benchmark |")
+	for _, unit := range units {
+		fmt.Printf(" old %s | new %s | delta |", unit, unit)
+	}
+	fmt.Print("\n|---|")
+	for range units {
+		fmt.Print("--:|--:|--:|")
+	}
+	fmt.Println()
+	var regressions []string
+	for _, name := range names {
+		fmt.Printf("| %s |", name)
+		for _, unit := range units {
+			o, okOld := before[name][unit]
+			n, okNew := after[name][unit]
+			if !okOld || !okNew {
+				fmt.Print(" - | - | - |")
+				continue
+			}
+			d := delta(o, n)
+			mark := ""
+			if d > *threshold {
+				mark = " ⚠"
+				regressions = append(regressions,
+					fmt.Sprintf("%s %s %+.1f%%", name, unit, d))
+			}
+			fmt.Printf(" %.0f | %.0f | %+.1f%%%s |", o, n, d, mark)
+		}
+		fmt.Println()
+	}
+
+	if len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d regressions over %.0f%%:\n",
+			len(regressions), *threshold)
+		for _, regression := range regressions {
+			fmt.Fprintln(os.Stderr, "  "+regression)
+		}
+		os.Exit(1)
+	}
+}
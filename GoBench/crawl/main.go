@@ -0,0 +1,366 @@
+// Crawl starts at an app's home page and follows every link, form, asset
+// and hx-get, hx-post and the like it finds, reporting broken routes,
+// missing static assets, methods routes don't accept and invalid
+// <base href>s.
+//
+//	go run ./crawl http://localhost:3000
+//
+// Pages are fetched the way a browser fetches them, and hx-* URLs the way
+// htmx does.  Forms are submitted empty, and requests other than GETs
+// are made from a session of their own, so a logout form doesn't end the
+// crawl's.  Links to other sites aren't followed.  Run the app with
+// -rate-limit=false.  Crawl exits with status 1 when it finds problems.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"example/bench/visit"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// A URL found on a page, and how it's requested.
+type link struct {
+	method string
+	url    *url.URL
+	kind   string // link, asset, form, htmx or redirect
+	from   string // the page it was found on, or "" for the start page
+}
+
+type problem struct {
+	kind   string
+	method string
+	url    string
+	from   string
+	detail string
+	// How many more pages have the same problem.
+	others int
+}
+
+type crawler struct {
+	origin   *url.URL
+	client   *http.Client
+	queue    []link
+	seen     map[string]bool
+	problems []problem
+	pages    int
+	external int
+}
+
+func (c *crawler) enqueue(l link) {
+	l.url.Fragment = ""
+	if l.url.Scheme != c.origin.Scheme || l.url.Host != c.origin.Host {
+		if l.url.Scheme == "http" || l.url.Scheme == "https" {
+			c.external++
+		}
+		return
+	}
+	key := l.method + " " + l.url.String()
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.queue = append(c.queue, l)
+}
+
+func (c *crawler) report(kind string, l link, detail string) {
+	c.problems = append(c.problems, problem{kind, l.method,
+		l.url.RequestURI(), l.from, detail, 0})
+}
+
+// Reports a problem with a page's own markup, once however many pages
+// share it, since most of them come from the layout.
+func (c *crawler) reportPage(kind string, page link, detail string) {
+	for i := range c.problems {
+		if p := &c.problems[i]; p.kind == kind && p.detail == detail &&
+			p.from == "" {
+			p.others++
+			return
+		}
+	}
+	page.from = ""
+	c.report(kind, page, detail)
+}
+
+func (c *crawler) crawl() error {
+	for len(c.queue) > 0 {
+		l := c.queue[0]
+		c.queue = c.queue[1:]
+		if err := c.fetch(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *crawler) fetch(l link) error {
+	var body io.Reader
+	if l.method != http.MethodGet {
+		body = strings.NewReader("")
+	}
+	req, err := http.NewRequest(l.method, l.url.String(), body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	from := c.origin.String()
+	if u, err := c.origin.Parse(l.from); err == nil {
+		from = u.String()
+	}
+	if l.kind == "htmx" {
+		visit.HTMX(req, from)
+	}
+	client := c.client
+	if l.method != http.MethodGet {
+		client = visit.NewClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch status := resp.StatusCode; {
+	case status == http.StatusMethodNotAllowed:
+		c.report("method", l, fmt.Sprintf("the route doesn't accept %s",
+			l.method))
+		return nil
+	case status == http.StatusTooManyRequests:
+		c.report("limited", l, "rate limited; run the app with "+
+			"-rate-limit=false")
+		return nil
+	case status == http.StatusNotFound && l.kind == "asset":
+		c.report("asset", l, "the static asset is missing")
+		return nil
+	case status >= 400 && status != http.StatusUnauthorized:
+		c.report("broken", l, resp.Status)
+		return nil
+	}
+	// Redirects, including htmx's, and 401s that say where to log in.
+	for _, header := range []string{"Location", "HX-Redirect"} {
+		if target := resp.Header.Get(header); target != "" {
+			if u, err := l.url.Parse(target); err == nil {
+				c.enqueue(link{http.MethodGet, u, "redirect",
+					l.url.RequestURI()})
+			}
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized &&
+		resp.Header.Get("HX-Redirect") == "" {
+		c.report("broken", l, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK || l.kind == "asset" ||
+		!strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		c.report("broken", l, fmt.Sprintf("unparsable HTML: %v", err))
+		return nil
+	}
+	c.pages++
+	c.scan(doc, l)
+	return nil
+}
+
+// Queues everything a page links to.
+func (c *crawler) scan(doc *html.Node, page link) {
+	from := page.url.RequestURI()
+	base := page.url
+	if n := find(doc, atom.Base); n != nil {
+		if href, ok := attr(n, "href"); ok {
+			u, err := page.url.Parse(href)
+			switch {
+			case err != nil:
+				c.reportPage("base", page, fmt.Sprintf("<base href=%q>: %v",
+					href, err))
+			case strings.Contains(href, "~"):
+				c.reportPage("base", page, fmt.Sprintf("<base href=%q> is an "+
+					"ASP.NET app-relative path; browsers take ~ for a "+
+					"directory, so relative URLs break", href))
+			default:
+				base = u
+			}
+		}
+	}
+
+	resolve := func(ref string) *url.URL {
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			c.reportPage("broken", page, fmt.Sprintf("unparsable URL %q", ref))
+			return nil
+		}
+		return u
+	}
+	walk(doc, func(n *html.Node) {
+		add := func(method, ref, kind string) {
+			if u := resolve(ref); u != nil {
+				c.enqueue(link{method, u, kind, from})
+			}
+		}
+		switch n.DataAtom {
+		case atom.A, atom.Area:
+			href, ok := attr(n, "href")
+			if !ok {
+				break
+			}
+			if strings.TrimSpace(href) == "" {
+				c.reportPage("href", page, fmt.Sprintf("<%s href=\"\"> %q links "+
+					"to the page it's on", n.Data, text(n)))
+				break
+			}
+			if strings.HasPrefix(href, "#") || isScript(href) {
+				break
+			}
+			add(http.MethodGet, href, "link")
+		case atom.Link:
+			if href, ok := attr(n, "href"); ok {
+				add(http.MethodGet, href, "asset")
+			}
+		case atom.Script, atom.Img, atom.Source, atom.Iframe:
+			if src, ok := attr(n, "src"); ok {
+				add(http.MethodGet, src, "asset")
+			}
+		case atom.Form:
+			method, _ := attr(n, "method")
+			action, ok := attr(n, "action")
+			if !ok {
+				action = page.url.String()
+			}
+			add(strings.ToUpper(orDefault(method, http.MethodGet)), action,
+				"form")
+		}
+		for _, verb := range []string{"get", "post", "put", "patch",
+			"delete"} {
+			if ref, ok := attr(n, "hx-"+verb); ok {
+				add(strings.ToUpper(verb), ref, "htmx")
+			}
+		}
+	})
+}
+
+func isScript(href string) bool {
+	scheme, _, found := strings.Cut(href, ":")
+	if !found {
+		return false
+	}
+	switch strings.ToLower(scheme) {
+	case "javascript", "mailto", "tel", "data":
+		return true
+	}
+	return false
+}
+
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func walk(n *html.Node, visit func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			visit(child)
+		}
+		walk(child, visit)
+	}
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(child *html.Node) {
+		if found == nil && child.DataAtom == a {
+			found = child
+		}
+	})
+	return found
+}
+
+func text(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data + " ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func main() {
+	user := flag.String("user", "crawl:crawl-password",
+		"name:password to log in as, so pages that need it get crawled; "+
+			"registered if the app doesn't know it, and empty to stay "+
+			"logged out")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"usage: crawl [flags] http://host:port\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	origin, err := url.Parse(strings.TrimRight(flag.Arg(0), "/") + "/")
+	if err != nil || origin.Host == "" {
+		log.Fatalf("%s: want http://host:port", flag.Arg(0))
+	}
+
+	c := &crawler{origin: origin, client: visit.NewClient(),
+		seen: make(map[string]bool)}
+	if *user != "" {
+		name, password, _ := strings.Cut(*user, ":")
+		base := strings.TrimRight(origin.String(), "/")
+		if err := visit.LogIn(c.client, base, name, password); err != nil {
+			log.Fatal(err)
+		}
+	}
+	c.enqueue(link{http.MethodGet, origin, "link", ""})
+	if err := c.crawl(); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, p := range c.problems {
+		fmt.Printf("%-7s %s %s\n", strings.ToUpper(p.kind), p.method, p.url)
+		if p.from != "" {
+			fmt.Printf("        found on %s\n", p.from)
+		}
+		fmt.Printf("        %s\n", p.detail)
+		if p.others > 0 {
+			fmt.Printf("        and so do %d other pages\n", p.others)
+		}
+	}
+	fmt.Printf("\ncrawled %d URLs, %d of them pages; %d problems; "+
+		"%d links to other sites not followed\n", len(c.seen), c.pages,
+		len(c.problems), c.external)
+	if len(c.problems) > 0 {
+		os.Exit(1)
+	}
+}
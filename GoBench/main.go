@@ -0,0 +1,184 @@
+// Bench drives the apps with scenarios that do what visitors do, and reports
+// throughput, latency percentiles, bytes transferred and, given an admin
+// to read expvar's memstats at /debug/vars as, server allocations.
+//
+//	go run . -duration 10s GoApp=http://localhost:3000 GoTemplApp=http://localhost:3001
+//
+// Run the apps with -rate-limit=false, or the polling and counter
+// scenarios mostly measure 429s.  To count allocations, make an admin with
+// likeBlazor adduser -admin and pass it to -admin.  Scenarios an app
+// can't run, like the counter when -user can't log in, are reported as
+// skipped, and apps whose counter posts its count, like RustAxum, need
+// -increment RustAxum=POST.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func parseTargets(args []string) ([]Target, error) {
+	var targets []Target
+	for _, arg := range args {
+		name, url, ok := strings.Cut(arg, "=")
+		if !ok {
+			// Name the app after its address.
+			name, url = arg, arg
+		}
+		if !strings.HasPrefix(url, "http://") &&
+			!strings.HasPrefix(url, "https://") {
+			return nil, fmt.Errorf("%s: want name=http://host:port", arg)
+		}
+		targets = append(targets, Target{Name: name,
+			URL: strings.TrimRight(url, "/")})
+	}
+	return targets, nil
+}
+
+// Sets the IncrementMethod of the targets named in list, which is like
+// "RustAxum=POST,Other=GET".  The rest use GET.
+func setIncrementMethods(targets []Target, list string) error {
+	for i := range targets {
+		targets[i].IncrementMethod = http.MethodGet
+	}
+	if list == "" {
+		return nil
+	}
+	for _, item := range strings.Split(list, ",") {
+		name, method, _ := strings.Cut(strings.TrimSpace(item), "=")
+		method = strings.ToUpper(method)
+		if method != http.MethodGet && method != http.MethodPost {
+			return fmt.Errorf("%s: want name=GET or name=POST", item)
+		}
+		found := false
+		for i := range targets {
+			if targets[i].Name == name {
+				targets[i].IncrementMethod = method
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: no app named %q", item, name)
+		}
+	}
+	return nil
+}
+
+func parseScenarios(list string) ([]Scenario, error) {
+	if list == "all" {
+		return scenarios, nil
+	}
+	var chosen []Scenario
+	for _, name := range strings.Split(list, ",") {
+		scenario, ok := findScenario(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("no scenario named %q", name)
+		}
+		chosen = append(chosen, scenario)
+	}
+	return chosen, nil
+}
+
+func main() {
+	var opts Options
+	flag.DurationVar(&opts.Duration, "duration", 10*time.Second,
+		"how long to run each scenario against each app")
+	flag.DurationVar(&opts.Warmup, "warmup", 2*time.Second,
+		"how long to run each scenario before measuring it")
+	flag.IntVar(&opts.Concurrency, "concurrency", 8,
+		"how many clients make requests at once")
+	user := flag.String("user", "bench:bench-password",
+		"name:password to log in as for scenarios that need it; "+
+			"registered if the app doesn't know it")
+	admin := flag.String("admin", "",
+		"name:password of an admin to read /debug/vars as, "+
+			"to count allocations")
+	increment := flag.String("increment", "",
+		"comma-separated name=POST for apps whose counter posts the count "+
+			"instead of getting /increment?count=")
+	scenarioList := flag.String("scenarios", "all",
+		"comma-separated scenarios to run, or all")
+	format := flag.String("format", "markdown", "markdown or json")
+	output := flag.String("o", "", "write the report to this file")
+	list := flag.Bool("list", false, "list the scenarios and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"usage: bench [flags] name=http://host:port...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		for _, scenario := range scenarios {
+			fmt.Printf("%-8s %s\n", scenario.Name, scenario.Description)
+		}
+		return
+	}
+	targets, err := parseTargets(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(targets) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := setIncrementMethods(targets, *increment); err != nil {
+		log.Fatal(err)
+	}
+	chosen, err := parseScenarios(*scenarioList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.User, opts.Password, _ = strings.Cut(*user, ":")
+	opts.Admin, opts.AdminPassword, _ = strings.Cut(*admin, ":")
+
+	report := Report{
+		Started:     time.Now(),
+		Duration:    opts.Duration.String(),
+		Concurrency: opts.Concurrency,
+	}
+	for _, scenario := range chosen {
+		for _, target := range targets {
+			log.Printf("running %s against %s", scenario.Name, target.Name)
+			result, err := Run(target, scenario, opts)
+			if err != nil {
+				log.Printf("skipping %s against %s: %v", scenario.Name,
+					target.Name, err)
+				report.Skipped = append(report.Skipped, Skipped{
+					Target:   target.Name,
+					Scenario: scenario.Name,
+					Reason:   err.Error(),
+				})
+				continue
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "json":
+		err = report.WriteJSON(w)
+	case "markdown":
+		err = report.WriteMarkdown(w)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// An element or a run of text, stripped of what doesn't change what a
+// page means: comments, whitespace between tags and the order of
+// attributes and classes.
+type node struct {
+	tag      string // empty for text
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+// Parses a page, or a fragment of one, replacing the strings in replace
+// wherever they appear.
+func parse(r io.Reader, replace *strings.Replacer) (*node, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return convert(doc, replace), nil
+}
+
+func convert(n *html.Node, replace *strings.Replacer) *node {
+	converted := &node{tag: "#document"}
+	if n.Type == html.ElementNode {
+		converted.tag = n.Data
+		converted.attrs = make(map[string]string)
+		for _, attr := range n.Attr {
+			value := replace.Replace(attr.Val)
+			if attr.Key == "class" {
+				classes := strings.Fields(value)
+				sort.Strings(classes)
+				value = strings.Join(classes, " ")
+			}
+			converted.attrs[attr.Key] = value
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch child.Type {
+		case html.ElementNode:
+			converted.children = append(converted.children,
+				convert(child, replace))
+		case html.TextNode:
+			text := strings.Join(strings.Fields(replace.Replace(child.Data)),
+				" ")
+			if text == "" {
+				continue
+			}
+			last := len(converted.children) - 1
+			if last >= 0 && converted.children[last].tag == "" {
+				converted.children[last].text += " " + text
+			} else {
+				converted.children = append(converted.children,
+					&node{text: text})
+			}
+		}
+	}
+	return converted
+}
+
+// What two children have to share to be compared with each other rather
+// than reported as missing from one side.
+func (n *node) signature() string {
+	if n.tag == "" {
+		return "#text"
+	}
+	return n.tag + "#" + n.attrs["id"]
+}
+
+// Like div#main-layout or a.nav-link.active.
+func (n *node) selector() string {
+	if n.tag == "" {
+		return "#text"
+	}
+	s := n.tag
+	if id := n.attrs["id"]; id != "" {
+		s += "#" + id
+	}
+	if class := n.attrs["class"]; class != "" {
+		s += "." + strings.ReplaceAll(class, " ", ".")
+	}
+	return s
+}
+
+// The node's start tag, or its text, and the start of the text inside it.
+func (n *node) describe() string {
+	if n.tag == "" {
+		return fmt.Sprintf("%q", shorten(n.text))
+	}
+	var b strings.Builder
+	b.WriteString("<" + n.tag)
+	for _, key := range sortedKeys(n.attrs) {
+		fmt.Fprintf(&b, " %s=%q", key, n.attrs[key])
+	}
+	b.WriteString(">")
+	if text := n.innerText(); text != "" {
+		b.WriteString(" " + fmt.Sprintf("%q", shorten(text)))
+	}
+	return b.String()
+}
+
+func (n *node) innerText() string {
+	if n.tag == "" {
+		return n.text
+	}
+	var parts []string
+	for _, child := range n.children {
+		if text := child.innerText(); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func shorten(s string) string {
+	if len(s) > 60 {
+		return s[:57] + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type Difference struct {
+	Path  string
+	What  string
+	Left  string
+	Right string
+}
+
+// The differences between two trees, with children lined up by the
+// longest common subsequence of their signatures, so one extra element
+// is reported as that rather than as every sibling after it differing.
+func diff(left, right *node, path string) []Difference {
+	var diffs []Difference
+	for _, key := range sortedKeys(union(left.attrs, right.attrs)) {
+		l, inLeft := left.attrs[key]
+		r, inRight := right.attrs[key]
+		switch {
+		case !inLeft:
+			diffs = append(diffs, Difference{path, "attribute " + key,
+				"missing", fmt.Sprintf("%q", r)})
+		case !inRight:
+			diffs = append(diffs, Difference{path, "attribute " + key,
+				fmt.Sprintf("%q", l), "missing"})
+		case l != r:
+			diffs = append(diffs, Difference{path, "attribute " + key,
+				fmt.Sprintf("%q", l), fmt.Sprintf("%q", r)})
+		}
+	}
+
+	pairs := align(left.children, right.children)
+	for _, pair := range pairs {
+		switch {
+		case pair.right == nil:
+			diffs = append(diffs, Difference{path, "element",
+				pair.left.describe(), "missing"})
+		case pair.left == nil:
+			diffs = append(diffs, Difference{path, "element", "missing",
+				pair.right.describe()})
+		case pair.left.tag == "":
+			if pair.left.text != pair.right.text {
+				diffs = append(diffs, Difference{path, "text",
+					fmt.Sprintf("%q", pair.left.text),
+					fmt.Sprintf("%q", pair.right.text)})
+			}
+		default:
+			childPath := pair.left.selector()
+			if path != "" {
+				childPath = path + " > " + childPath
+			}
+			diffs = append(diffs, diff(pair.left, pair.right, childPath)...)
+		}
+	}
+	return diffs
+}
+
+func union(a, b map[string]string) map[string]string {
+	u := make(map[string]string, len(a)+len(b))
+	for key := range a {
+		u[key] = ""
+	}
+	for key := range b {
+		u[key] = ""
+	}
+	return u
+}
+
+// Children matched up with each other, or with nil when only one side
+// has them.
+type pair struct {
+	left, right *node
+}
+
+func align(left, right []*node) []pair {
+	// lengths[i][j] is the length of the longest common subsequence of
+	// left[i:] and right[j:].
+	lengths := make([][]int, len(left)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i].signature() == right[j].signature() {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var pairs []pair
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i].signature() == right[j].signature():
+			pairs = append(pairs, pair{left[i], right[j]})
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			pairs = append(pairs, pair{left[i], nil})
+			i++
+		default:
+			pairs = append(pairs, pair{nil, right[j]})
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		pairs = append(pairs, pair{left[i], nil})
+	}
+	for ; j < len(right); j++ {
+		pairs = append(pairs, pair{nil, right[j]})
+	}
+	return pairs
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Children with the given tags, or text for "#text".
+func nodes(tags string) []*node {
+	var children []*node
+	for _, tag := range strings.Fields(tags) {
+		if tag == "#text" {
+			tag = ""
+		}
+		children = append(children, &node{tag: tag, text: "x"})
+	}
+	return children
+}
+
+// Like "p=p" for a match, "p-" when only the left has it and "+p" when
+// only the right does.
+func (p pair) String() string {
+	switch {
+	case p.right == nil:
+		return p.left.signature() + "-"
+	case p.left == nil:
+		return "+" + p.right.signature()
+	}
+	return p.left.signature() + "=" + p.right.signature()
+}
+
+func TestAlign(t *testing.T) {
+	for _, test := range []struct {
+		name, left, right string
+		want              []string
+	}{
+		{"same", "h1 p ul", "h1 p ul",
+			[]string{"h1#=h1#", "p#=p#", "ul#=ul#"}},
+		{"empty", "", "", nil},
+		{"insert", "h1 ul", "h1 p ul",
+			[]string{"h1#=h1#", "+p#", "ul#=ul#"}},
+		{"insert first", "p", "h1 p", []string{"+h1#", "p#=p#"}},
+		{"insert last", "h1", "h1 p", []string{"h1#=h1#", "+p#"}},
+		{"delete", "h1 p ul", "h1 ul",
+			[]string{"h1#=h1#", "p#-", "ul#=ul#"}},
+		{"delete all", "h1 p", "", []string{"h1#-", "p#-"}},
+		{"replace", "h1 p", "h1 div",
+			[]string{"h1#=h1#", "p#-", "+div#"}},
+		{"reorder", "h1 p ul", "ul h1 p",
+			[]string{"+ul#", "h1#=h1#", "p#=p#", "ul#-"}},
+		{"swap", "h1 p", "p h1", []string{"h1#-", "p#=p#", "+h1#"}},
+		{"text", "#text h1", "h1 #text",
+			[]string{"#text-", "h1#=h1#", "+#text"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, p := range align(nodes(test.left), nodes(test.right)) {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("align(%q, %q) = %v, want %v", test.left,
+					test.right, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	page := func(body string) *node {
+		t.Helper()
+		n, err := parse(strings.NewReader(body), strings.NewReplacer())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	// Siblings only line up by tag and id, so the lists' items have ids.
+	for _, test := range []struct {
+		name, left, right string
+		want              []string
+	}{
+		{"attribute and class order",
+			`<p class="a b" id="x">hi</p>`, `<p id="x" class="b  a"> hi </p>`,
+			nil},
+		{"extra item",
+			`<ul><li id="one">1</li><li id="three">3</li></ul>`,
+			`<ul><li id="one">1</li><li id="two">2</li><li id="three">3</li></ul>`,
+			[]string{`element: missing / <li id="two"> "2"`}},
+		{"attribute",
+			`<a href="/a">a</a>`, `<a href="/b">a</a>`,
+			[]string{`attribute href: "/a" / "/b"`}},
+		{"text", `<p>one</p>`, `<p>two</p>`,
+			[]string{`text: "one" / "two"`}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, d := range diff(page(test.left), page(test.right), "") {
+				got = append(got, d.What+": "+d.Left+" / "+d.Right)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
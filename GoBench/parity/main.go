@@ -0,0 +1,215 @@
+// Parity fetches every route from two apps that are meant to produce the
+// same markup, like GoApp and GoTemplApp, and reports how their pages
+// differ: missing or extra elements, attributes and text.  Whitespace,
+// comments, attribute order, CSP nonces, request IDs and asset
+// fingerprints are ignored.
+//
+//	go run ./parity GoApp=http://localhost:3000 GoTemplApp=http://localhost:3001
+//
+// It exits with status 1 when any page differs.  Run the apps with
+// -rate-limit=false.  The admin pages are only compared given -admin, an
+// admin both apps know, made with likeBlazor adduser -admin.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"example/bench/visit"
+	"example/server"
+)
+
+// Who requests a route.
+const (
+	visitor = "visitor"
+	member  = "member"
+	admin   = "admin"
+)
+
+type route struct {
+	method  string
+	path    string
+	variant server.Variant
+	as      string
+	// Routes whose text is random, so only their markup is compared.
+	randomText bool
+}
+
+// Every page and fragment of the apps' route table, requested each way
+// it gets requested, and the not found page.  JSON is for tools, and
+// redirects have no markup, so they're left out.
+func routes() []route {
+	var list []route
+	for _, req := range server.RouteRequests() {
+		if req.Route.Response == server.JSONResponse ||
+			req.Route.Response == server.RedirectResponse {
+			continue
+		}
+		as := visitor
+		if slices.Contains(req.Route.Roles, server.RoleAdmin) {
+			as = admin
+		} else if len(req.Route.Roles) > 0 {
+			as = member
+		}
+		list = append(list, route{req.Method, req.Target, req.Variant, as,
+			req.Route.Name == server.RouteForecasts})
+	}
+	return append(list,
+		route{"GET", "/missing", server.PageVariant, visitor, false},
+		route{"GET", "/missing", server.FragmentVariant, visitor, false},
+	)
+}
+
+type app struct {
+	name    string
+	url     string
+	clients map[string]*http.Client
+}
+
+func parseApp(arg string) (*app, error) {
+	name, url, ok := strings.Cut(arg, "=")
+	if !ok || !strings.HasPrefix(url, "http") {
+		return nil, fmt.Errorf("%s: want name=http://host:port", arg)
+	}
+	return &app{
+		name: name,
+		url:  strings.TrimRight(url, "/"),
+		clients: map[string]*http.Client{
+			visitor: visit.NewClient(),
+			member:  visit.NewClient(),
+			admin:   visit.NewClient(),
+		},
+	}, nil
+}
+
+var (
+	hashedAsset = regexp.MustCompile(`\.[0-9a-f]{10}\.(css|js)\b`)
+	policyNonce = regexp.MustCompile(`'nonce-([^']+)'`)
+)
+
+// Fetches a route and parses it, leaving out what differs between any two
+// responses, even from the same app.
+func (a *app) fetch(r route) (*node, int, error) {
+	req, err := http.NewRequest(r.method, a.url+r.path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch r.variant {
+	case server.BoostedVariant:
+		visit.Boosted(req, a.url+"/")
+	case server.FragmentVariant:
+		visit.HTMX(req, a.url+"/")
+	}
+	resp, err := a.clients[r.as].Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	replacements := []string{}
+	if match := policyNonce.FindStringSubmatch(
+		resp.Header.Get("Content-Security-Policy")); match != nil {
+		replacements = append(replacements, match[1], "NONCE")
+	}
+	if id := resp.Header.Get("X-Request-Id"); id != "" {
+		replacements = append(replacements, id, "REQUEST-ID")
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	body = hashedAsset.ReplaceAll(body, []byte(".HASH.$1"))
+	root, err := parse(bytes.NewReader(body),
+		strings.NewReplacer(replacements...))
+	return root, resp.StatusCode, err
+}
+
+func main() {
+	user := flag.String("user", "parity:parity-password",
+		"name:password to log in as for routes that need it; "+
+			"registered if an app doesn't know it")
+	adminUser := flag.String("admin", "",
+		"name:password of an admin to compare the admin pages as")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"usage: parity [flags] name=http://host:port name=http://host:port\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var apps [2]*app
+	for i := range apps {
+		var err error
+		if apps[i], err = parseApp(flag.Arg(i)); err != nil {
+			log.Fatal(err)
+		}
+		name, password, _ := strings.Cut(*user, ":")
+		if err := visit.LogIn(apps[i].clients[member], apps[i].url, name,
+			password); err != nil {
+			log.Fatal(err)
+		}
+		if *adminUser != "" {
+			name, password, _ := strings.Cut(*adminUser, ":")
+			if err := visit.LogIn(apps[i].clients[admin], apps[i].url,
+				name, password); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	left, right := apps[0], apps[1]
+
+	differing, compared := 0, 0
+	for _, r := range routes() {
+		if r.as == admin && *adminUser == "" {
+			fmt.Printf("skipped   %s %s (%s): no -admin\n", r.method, r.path,
+				r.variant)
+			continue
+		}
+		compared++
+		l, lStatus, err := left.fetch(r)
+		if err != nil {
+			log.Fatalf("%s %s: %v", left.name, r.path, err)
+		}
+		rt, rStatus, err := right.fetch(r)
+		if err != nil {
+			log.Fatalf("%s %s: %v", right.name, r.path, err)
+		}
+
+		var diffs []Difference
+		for _, d := range diff(l, rt, "") {
+			if d.What != "text" || !r.randomText {
+				diffs = append(diffs, d)
+			}
+		}
+		if lStatus != rStatus {
+			diffs = append([]Difference{{"", "status",
+				fmt.Sprint(lStatus), fmt.Sprint(rStatus)}}, diffs...)
+		}
+		if len(diffs) == 0 {
+			fmt.Printf("same      %s %s (%s)\n", r.method, r.path, r.variant)
+			continue
+		}
+		differing++
+		fmt.Printf("DIFFERENT %s %s (%s): %d differences\n", r.method,
+			r.path, r.variant, len(diffs))
+		for _, d := range diffs {
+			fmt.Printf("  %s\n    %s\n    %s: %s\n    %s: %s\n",
+				d.Path, d.What, left.name, d.Left, right.name, d.Right)
+		}
+	}
+	fmt.Printf("\n%d of %d routes differ\n", differing, compared)
+	if differing > 0 {
+		os.Exit(1)
+	}
+}
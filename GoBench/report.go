@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Report struct {
+	Started     time.Time `json:"started"`
+	Duration    string    `json:"duration"`
+	Concurrency int       `json:"concurrency"`
+	Results     []Result  `json:"results"`
+	Skipped     []Skipped `json:"skipped,omitempty"`
+}
+
+// A scenario that couldn't be run against an app, and why.
+type Skipped struct {
+	Target   string `json:"target"`
+	Scenario string `json:"scenario"`
+	Reason   string `json:"reason"`
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Writes a table per scenario, so the apps can be compared row by row.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Benchmark %s\n\n", r.Started.Format(time.RFC3339))
+	fmt.Fprintf(&b, "%d concurrent clients for %s per scenario.\n",
+		r.Concurrency, r.Duration)
+
+	var order []string
+	seen := make(map[string]bool)
+	byScenario := make(map[string][]Result)
+	for _, result := range r.Results {
+		if !seen[result.Scenario] {
+			seen[result.Scenario] = true
+			order = append(order, result.Scenario)
+		}
+		byScenario[result.Scenario] = append(byScenario[result.Scenario],
+			result)
+	}
+	skipped := make(map[string][]Skipped)
+	for _, skip := range r.Skipped {
+		if !seen[skip.Scenario] {
+			seen[skip.Scenario] = true
+			order = append(order, skip.Scenario)
+		}
+		skipped[skip.Scenario] = append(skipped[skip.Scenario], skip)
+	}
+	for _, name := range order {
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		if scenario, ok := findScenario(name); ok {
+			fmt.Fprintf(&b, "%s.\n\n", capitalize(scenario.Description))
+		}
+		for _, skip := range skipped[name] {
+			fmt.Fprintf(&b, "Skipped for %s: %s.\n\n", skip.Target,
+				skip.Reason)
+		}
+		if len(byScenario[name]) == 0 {
+			continue
+		}
+		b.WriteString("| app | req/s | p50 ms | p90 ms | p99 ms | max ms " +
+			"| bytes/req | allocs/req | alloc bytes/req | errors | statuses |\n")
+		b.WriteString("|---|--:|--:|--:|--:|--:|--:|--:|--:|--:|---|\n")
+		for _, result := range byScenario[name] {
+			fmt.Fprintf(&b, "| %s | %.0f | %.2f | %.2f | %.2f | %.2f "+
+				"| %.0f | %s | %s | %d | %s |\n",
+				result.Target, result.Throughput,
+				result.LatencyMs.P50, result.LatencyMs.P90,
+				result.LatencyMs.P99, result.LatencyMs.Max,
+				result.BytesPerReq, optional(result.AllocsPerReq),
+				optional(result.AllocBytesPerReq), result.Errors,
+				statusList(result.Statuses))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func optional(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", *value)
+}
+
+// Like "200×980 429×12".
+func statusList(statuses map[string]int) string {
+	var codes []string
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for i, code := range codes {
+		codes[i] = fmt.Sprintf("%s×%d", code, statuses[code])
+	}
+	return strings.Join(codes, " ")
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
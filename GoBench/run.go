@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"example/bench/visit"
+)
+
+type Options struct {
+	Duration    time.Duration
+	Warmup      time.Duration
+	Concurrency int
+	User        string
+	Password    string
+	// Who to read /debug/vars as, which only admins may.
+	Admin         string
+	AdminPassword string
+}
+
+// An app to benchmark, like GoApp=http://localhost:3000.
+type Target struct {
+	Name string
+	URL  string
+	// How the counter's button sends the count: GET, with it in the
+	// query, or POST, with it in a form.
+	IncrementMethod string
+}
+
+type Result struct {
+	Target      string         `json:"target"`
+	Scenario    string         `json:"scenario"`
+	Requests    int            `json:"requests"`
+	Errors      int            `json:"errors"`
+	Statuses    map[string]int `json:"statuses"`
+	Seconds     float64        `json:"seconds"`
+	Throughput  float64        `json:"requestsPerSecond"`
+	LatencyMs   Percentiles    `json:"latencyMs"`
+	Bytes       int64          `json:"bytes"`
+	BytesPerReq float64        `json:"bytesPerRequest"`
+	// Only known when there's an admin to read /debug/vars as.
+	AllocsPerReq     *float64 `json:"allocsPerRequest,omitempty"`
+	AllocBytesPerReq *float64 `json:"allocBytesPerRequest,omitempty"`
+}
+
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// What one worker saw.
+type sample struct {
+	latencies []time.Duration
+	statuses  map[string]int
+	errors    int
+	bytes     int64
+}
+
+// Runs scenario against target with opts.Concurrency workers for
+// opts.Duration, after opts.Warmup of requests that aren't measured.  It
+// fails without running anything if the workers can't log in.
+func Run(target Target, scenario Scenario, opts Options) (Result, error) {
+	clients := make([]*http.Client, opts.Concurrency)
+	for i := range clients {
+		clients[i] = visit.NewClient()
+		if scenario.NeedsLogin && opts.User != "" {
+			err := visit.LogIn(clients[i], target.URL, opts.User,
+				opts.Password)
+			if err != nil && !errors.Is(err, visit.ErrNoAccounts) {
+				return Result{}, err
+			}
+		}
+	}
+
+	var admin *http.Client
+	if opts.Admin != "" {
+		admin = visit.NewClient()
+		if err := visit.LogIn(admin, target.URL, opts.Admin,
+			opts.AdminPassword); err != nil {
+			log.Printf("not counting allocations: %v", err)
+			admin = nil
+		}
+	}
+
+	if opts.Warmup > 0 {
+		hammer(target, scenario, clients, opts.Warmup)
+	}
+	before, _ := readMemStats(admin, target)
+	start := time.Now()
+	samples := hammer(target, scenario, clients, opts.Duration)
+	elapsed := time.Since(start)
+	after, _ := readMemStats(admin, target)
+
+	result := Result{
+		Target:   target.Name,
+		Scenario: scenario.Name,
+		Statuses: make(map[string]int),
+		Seconds:  elapsed.Seconds(),
+	}
+	var latencies []time.Duration
+	for _, s := range samples {
+		latencies = append(latencies, s.latencies...)
+		result.Errors += s.errors
+		result.Bytes += s.bytes
+		for status, n := range s.statuses {
+			result.Statuses[status] += n
+		}
+	}
+	result.Requests = len(latencies)
+	if result.Requests == 0 {
+		return result, fmt.Errorf("%s %s: no requests finished",
+			target.Name, scenario.Name)
+	}
+	result.Throughput = float64(result.Requests) / elapsed.Seconds()
+	result.BytesPerReq = float64(result.Bytes) / float64(result.Requests)
+	result.LatencyMs = percentiles(latencies)
+	if before != nil && after != nil {
+		// Reading the stats allocates too, but not enough to matter.
+		allocs := float64(after.Mallocs-before.Mallocs) /
+			float64(result.Requests)
+		allocBytes := float64(after.TotalAlloc-before.TotalAlloc) /
+			float64(result.Requests)
+		result.AllocsPerReq, result.AllocBytesPerReq = &allocs, &allocBytes
+	}
+	return result, nil
+}
+
+// Makes requests with every client at once until duration is up.
+func hammer(target Target, scenario Scenario, clients []*http.Client,
+	duration time.Duration) []*sample {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	samples := make([]*sample, len(clients))
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		samples[i] = &sample{statuses: make(map[string]int)}
+		wg.Add(1)
+		go func(s *sample, client *http.Client) {
+			defer wg.Done()
+			for n := 0; ctx.Err() == nil; n++ {
+				req, err := scenario.Request(target, n)
+				if err != nil {
+					s.errors++
+					continue
+				}
+				start := time.Now()
+				resp, err := client.Do(req.WithContext(ctx))
+				if err != nil {
+					// Requests cut off when time's up don't count.
+					if !errors.Is(err, context.DeadlineExceeded) {
+						s.errors++
+						s.statuses["error"]++
+					}
+					continue
+				}
+				written, err := io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if err != nil && ctx.Err() != nil {
+					continue
+				}
+				s.latencies = append(s.latencies, time.Since(start))
+				s.bytes += written
+				s.statuses[strconv.Itoa(resp.StatusCode)]++
+				if resp.StatusCode >= 400 {
+					s.errors++
+				}
+			}
+		}(samples[i], client)
+	}
+	wg.Wait()
+	return samples
+}
+
+func percentiles(latencies []time.Duration) Percentiles {
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	at := func(p float64) float64 {
+		i := int(p * float64(len(latencies)-1))
+		return float64(latencies[i]) / float64(time.Millisecond)
+	}
+	return Percentiles{P50: at(0.50), P90: at(0.90), P99: at(0.99), Max: at(1)}
+}
+
+type memStats struct {
+	Mallocs    uint64
+	TotalAlloc uint64
+}
+
+// The app's allocation counters, from expvar's memstats at /debug/vars,
+// read by admin, a client logged in as an admin.
+func readMemStats(admin *http.Client, target Target) (*memStats, error) {
+	if admin == nil {
+		return nil, fmt.Errorf("no admin to read %s/debug/vars as",
+			target.URL)
+	}
+	resp, err := admin.Get(target.URL + "/debug/vars")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s/debug/vars: %s", target.URL, resp.Status)
+	}
+	var vars struct {
+		MemStats *memStats `json:"memstats"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return nil, err
+	}
+	if vars.MemStats == nil {
+		return nil, fmt.Errorf("%s/debug/vars has no memstats", target.URL)
+	}
+	return vars.MemStats, nil
+}
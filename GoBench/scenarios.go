@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"example/bench/visit"
+	"example/server"
+)
+
+// Something a visitor does over and over.  Request builds a worker's nth
+// request against target.
+type Scenario struct {
+	Name        string
+	Description string
+	// Whether the app only serves the scenario to logged in users, if it
+	// has users at all.
+	NeedsLogin bool
+	Request    func(target Target, n int) (*http.Request, error)
+}
+
+// The pages the apps' page cache keeps, which render the same for every
+// visitor.
+var pages = cachedPages()
+
+func cachedPages() []string {
+	var paths []string
+	for _, route := range server.Routes() {
+		if route.Method == http.MethodGet && route.Cached {
+			paths = append(paths, route.Path)
+		}
+	}
+	return paths
+}
+
+var scenarios = []Scenario{
+	{
+		Name:        "page",
+		Description: "full page loads of the home, about and fetch data pages",
+		Request: func(target Target, n int) (*http.Request, error) {
+			return http.NewRequest(http.MethodGet,
+				target.URL+pages[n%len(pages)], nil)
+		},
+	},
+	{
+		Name:        "boosted",
+		Description: "htmx-boosted navigation between the same pages",
+		Request: func(target Target, n int) (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodGet,
+				target.URL+pages[n%len(pages)], nil)
+			if err != nil {
+				return nil, err
+			}
+			visit.Boosted(req,
+				target.URL+pages[(n+len(pages)-1)%len(pages)])
+			return req, nil
+		},
+	},
+	{
+		Name:        "counter",
+		Description: "clicks on the counter page's button",
+		NeedsLogin:  true,
+		Request: func(target Target, n int) (*http.Request, error) {
+			query := url.Values{"count": {strconv.Itoa(n + 1)}}.Encode()
+			var req *http.Request
+			var err error
+			if target.IncrementMethod == http.MethodPost {
+				req, err = http.NewRequest(http.MethodPost,
+					target.URL+"/increment", strings.NewReader(query))
+				if err == nil {
+					req.Header.Set("Content-Type",
+						"application/x-www-form-urlencoded")
+				}
+			} else {
+				req, err = http.NewRequest(http.MethodGet,
+					target.URL+"/increment?"+query, nil)
+			}
+			if err != nil {
+				return nil, err
+			}
+			visit.HTMX(req, target.URL+"/counter")
+			return req, nil
+		},
+	},
+	{
+		Name:        "poll",
+		Description: "the fetch data page polling for forecasts",
+		Request: func(target Target, n int) (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodPost,
+				target.URL+"/forecasts", strings.NewReader(""))
+			if err != nil {
+				return nil, err
+			}
+			visit.HTMX(req, target.URL+"/fetchdata")
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return req, nil
+		},
+	},
+}
+
+func findScenario(name string) (Scenario, bool) {
+	for _, scenario := range scenarios {
+		if scenario.Name == name {
+			return scenario, true
+		}
+	}
+	return Scenario{}, false
+}
@@ -0,0 +1,69 @@
+// Package visit makes requests to the apps the way a visitor's browser and
+// htmx do.
+package visit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"time"
+)
+
+// A client with its own cookies, so it keeps its own session.  It doesn't
+// follow redirects, so they can be reported rather than the page they
+// lead to.
+func NewClient() *http.Client {
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{
+		Jar:     jar,
+		Timeout: 30 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{MaxIdleConnsPerHost: 1024},
+	}
+}
+
+// Sets the headers htmx sends with every request it makes.
+func HTMX(req *http.Request, currentURL string) {
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Current-URL", currentURL)
+}
+
+// Sets the headers htmx sends when hx-boost turns a link into a request
+// for the page.
+func Boosted(req *http.Request, currentURL string) {
+	HTMX(req, currentURL)
+	req.Header.Set("HX-Boosted", "true")
+	req.Header.Set("HX-Target", "main-layout")
+}
+
+// What LogIn returns for apps without accounts, which serve everything to
+// everyone.
+var ErrNoAccounts = errors.New("the app has no accounts")
+
+// Logs client in to the app at base as name, registering name first if
+// the app doesn't know it.
+func LogIn(client *http.Client, base, name, password string) error {
+	form := url.Values{
+		"username": {name},
+		"password": {password},
+		"confirm":  {password},
+	}
+	for _, path := range []string{"/login", "/register"} {
+		resp, err := client.PostForm(base+path, form)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusSeeOther {
+			return nil
+		}
+		if resp.StatusCode == http.StatusNotFound && path == "/login" {
+			return fmt.Errorf("%s: %w", base, ErrNoAccounts)
+		}
+	}
+	return fmt.Errorf("couldn't log in to %s as %s", base, name)
+}
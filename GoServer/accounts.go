@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// What the login and register forms show.
+type AccountForm struct {
+	Username string
+	Next     string
+	Error    string
+}
+
+// Only follow redirects to paths on this site.  Browsers drop tabs and
+// newlines from URLs and take backslashes for slashes, so /\t/evil.com
+// would be //evil.com to them; values with any of those are refused.
+func localPath(next string) string {
+	if strings.ContainsFunc(next, func(r rune) bool {
+		return r == '\\' || unicode.IsControl(r)
+	}) {
+		return "/"
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" ||
+		!strings.HasPrefix(u.Path, "/") || strings.HasPrefix(u.Path, "//") {
+		return "/"
+	}
+	return u.RequestURI()
+}
+
+// Sends the browser to path: with HX-Redirect for htmx requests, so the
+// whole page, nav menu included, reflects the new login state.
+func redirectPage(c *fiber.Ctx, path string) error {
+	if c.Get("HX-Request") == "true" {
+		c.Set("HX-Redirect", path)
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	return c.Redirect(path, fiber.StatusSeeOther)
+}
+
+func (s *site) showLogin(c *fiber.Ctx) error {
+	return s.views.Login(c, AccountForm{Next: localPath(c.Query("next"))})
+}
+
+func (s *site) login(c *fiber.Ctx) error {
+	form := AccountForm{
+		Username: c.FormValue("username"),
+		Next:     localPath(c.FormValue("next")),
+	}
+	user, err := s.users.Authenticate(form.Username, c.FormValue("password"))
+	if err != nil {
+		form.Error = err.Error()
+		return s.views.Login(c, form)
+	}
+	if err := CurrentSession(c).SetUsername(user.Name); err != nil {
+		return err
+	}
+	return redirectPage(c, form.Next)
+}
+
+func (s *site) showRegister(c *fiber.Ctx) error {
+	return s.views.Register(c, AccountForm{Next: localPath(c.Query("next"))})
+}
+
+func (s *site) register(c *fiber.Ctx) error {
+	form := AccountForm{
+		Username: c.FormValue("username"),
+		Next:     localPath(c.FormValue("next")),
+	}
+	password := c.FormValue("password")
+	if password != c.FormValue("confirm") {
+		form.Error = "the passwords don't match"
+		return s.views.Register(c, form)
+	}
+	user, err := s.users.Register(form.Username, password)
+	if err == ErrUserExists || err == ErrBadUserName ||
+		err == ErrShortPassword {
+		form.Error = err.Error()
+		return s.views.Register(c, form)
+	} else if err != nil {
+		return err
+	}
+	if err := CurrentSession(c).SetUsername(user.Name); err != nil {
+		return err
+	}
+	return redirectPage(c, form.Next)
+}
+
+func (s *site) logout(c *fiber.Ctx) error {
+	if err := CurrentSession(c).SetUsername(""); err != nil {
+		return err
+	}
+	return redirectPage(c, URLFor(RouteHome))
+}
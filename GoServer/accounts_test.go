@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	// As they appear in query strings, before fiber decodes them.
+	for _, test := range []struct {
+		next, want string
+	}{
+		{"/counter", "/counter"},
+		{"/login%3Fnext%3D%252Fcounter", "/login?next=%2Fcounter"},
+		{"", "/"},
+		{"counter", "/"},
+		{"https://evil.com/", "/"},
+		{"//evil.com", "/"},
+		{"%2F%2Fevil.com", "/"},
+		{"/%5Cevil.com", "/"},
+		{"/%09/evil.com", "/"},
+		{"/%0D%0A/evil.com", "/"},
+		{"javascript:alert(1)", "/"},
+	} {
+		next, err := url.QueryUnescape(test.next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := localPath(next); got != test.want {
+			t.Errorf("localPath(%q) = %q, want %q", next, got, test.want)
+		}
+	}
+}
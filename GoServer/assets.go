@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Maps the files under wwwroot to fingerprinted URLs that contain a hash
+// of their content, like /css/site.3f2a9c1b0d.css, so they can be cached
+// forever: a changed file gets a new URL.
+type AssetManifest struct {
+	urls  map[string]string
+	files map[string]string
+}
+
+// The manifest templates resolve asset URLs with.  Empty until loaded, which
+// leaves URLs as they are.
+var assets = &AssetManifest{}
+
+func fingerprint(name string, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+func LoadAssetManifest(root string) (*AssetManifest, error) {
+	m := &AssetManifest{
+		urls:  make(map[string]string),
+		files: make(map[string]string),
+	}
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || isVariant(file) ||
+			strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		name := "/" + filepath.ToSlash(rel)
+		url := fingerprint(name, hex.EncodeToString(hash.Sum(nil))[:10])
+		m.urls[name] = url
+		m.files[url] = name
+		return nil
+	})
+	return m, err
+}
+
+// The fingerprinted URL of the asset at path, or path itself for files the
+// manifest doesn't know.
+func (m *AssetManifest) URL(path string) string {
+	if url, ok := m.urls[path]; ok {
+		return url
+	}
+	return path
+}
+
+// The path of the file a fingerprinted URL refers to.
+func (m *AssetManifest) File(url string) (string, bool) {
+	name, ok := m.files[url]
+	return name, ok
+}
+
+// Resolves asset URLs in templates.
+func Asset(path string) string {
+	return assets.URL(path)
+}
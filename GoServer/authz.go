@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *User) RoleList() string {
+	return strings.Join(u.Roles, ", ")
+}
+
+// What the current visitor may do.
+type Access struct {
+	User *User
+}
+
+// Routing ignores case and trailing slashes, so lookups have to as well.
+func permissionKey(path string) string {
+	if path != "/" {
+		path = strings.TrimRight(path, "/")
+	}
+	return strings.ToLower(path)
+}
+
+func (a Access) Can(path string) bool {
+	roles := routeRoles(path)
+	if len(roles) == 0 {
+		return true
+	}
+	if a.User == nil {
+		return false
+	}
+	for _, role := range roles {
+		if a.User.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// The current visitor's access, as decided by the authorize middleware.
+func CurrentAccess(c *fiber.Ctx) Access {
+	user, _ := c.Locals("user").(*User)
+	return Access{User: user}
+}
+
+// Middleware that looks up the logged in user and checks the roles the
+// route table says the path needs.  Visitors who aren't logged in are
+// sent to the login page, and then back to where they were going.
+// Logged in users without the roles get a 403.
+func authorize(users *UserStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := users.Get(CurrentSession(c).Username())
+		c.Locals("user", user)
+		if (Access{User: user}).Can(c.Path()) {
+			return c.Next()
+		}
+		if user != nil {
+			return fiber.ErrForbidden
+		}
+		return redirectToLogin(c)
+	}
+}
+
+func redirectToLogin(c *fiber.Ctx) error {
+	next := c.OriginalURL()
+	if isFragmentRequest(c) {
+		// Come back to the page that made the request, not the fragment.
+		next = "/"
+		if current, err := url.Parse(c.Get("HX-Current-URL")); err == nil {
+			next = current.RequestURI()
+		}
+	}
+	target := URLFor(RouteLogin, "next", next)
+	if isFragmentRequest(c) {
+		c.Set("HX-Redirect", target)
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	return c.Redirect(target, fiber.StatusSeeOther)
+}
@@ -0,0 +1,54 @@
+package server_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"example/server/servertest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRedirects(t *testing.T) {
+	app := servertest.NewApp(t, stub,
+		servertest.WithAdmin(t, time.Now()))
+
+	resp := servertest.Request(t, app, "GET", "/counter", nil, nil, nil)
+	if resp.StatusCode != fiber.StatusSeeOther ||
+		resp.Header.Get("Location") != "/login?next=%2Fcounter" {
+		t.Errorf("/counter when logged out: got %s to %q", resp.Status,
+			resp.Header.Get("Location"))
+	}
+
+	resp = servertest.Request(t, app, "GET", "/increment?count=1", nil,
+		map[string]string{
+			"HX-Request":     "true",
+			"HX-Current-URL": "http://localhost/counter",
+		}, nil)
+	if resp.StatusCode != fiber.StatusUnauthorized ||
+		resp.Header.Get("HX-Redirect") != "/login?next=%2Fcounter" {
+		t.Errorf("/increment when logged out: got %s to %q", resp.Status,
+			resp.Header.Get("HX-Redirect"))
+	}
+
+	admin := servertest.LogInAsAdmin(t, app)
+	resp = servertest.Request(t, app, "POST", "/logout",
+		strings.NewReader(""), nil, admin)
+	if resp.StatusCode != fiber.StatusSeeOther ||
+		resp.Header.Get("Location") != "/" {
+		t.Errorf("/logout: got %s to %q", resp.Status,
+			resp.Header.Get("Location"))
+	}
+
+	form := url.Values{"username": {"admin"}, "password": {"password1"},
+		"next": {"/about"}}
+	resp = servertest.Request(t, app, "POST", "/login",
+		strings.NewReader(form.Encode()), servertest.Boosted, nil)
+	if resp.StatusCode != fiber.StatusNoContent ||
+		resp.Header.Get("HX-Redirect") != "/about" {
+		t.Errorf("boosted /login: got %s to %q", resp.Status,
+			resp.Header.Get("HX-Redirect"))
+	}
+}
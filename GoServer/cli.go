@@ -0,0 +1,360 @@
+package server
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// A subcommand of the binary, like likeBlazor routes.
+type command struct {
+	name    string
+	summary string
+	run     func(f *Frontend, args []string) error
+}
+
+var commands = []command{
+	{"serve", "start the server; the default", serveCommand},
+	{"routes", "print the routes, their methods and handlers",
+		routesCommand},
+	{"render", "render a page to stdout", renderCommand},
+	{"check", "load the templates and render every route",
+		checkCommand},
+	{"export", "export the static pages and assets to a directory",
+		exportCommand},
+	{"adduser", "add a user to the users file, or make one an admin",
+		adduserCommand},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: likeBlazor [command] [flags]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun likeBlazor <command> -h for a command's flags.\n")
+}
+
+// Runs the command args name, or serve when they start with a flag or
+// there are none, so likeBlazor -addr :3000 still starts the server.
+func (f *Frontend) Run(args []string) error {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage(os.Stdout)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(f, args)
+		}
+	}
+	usage(os.Stderr)
+	os.Exit(2)
+	return nil
+}
+
+// A flag set for a command that takes args, with the config's flags on
+// it.
+func commandFlags(name, args string, cfg *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cfg.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: likeBlazor %s [flags] %s\n", name,
+			args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func loadAssets() error {
+	manifest, err := LoadAssetManifest("./wwwroot")
+	if err != nil {
+		return err
+	}
+	assets = manifest
+	return nil
+}
+
+func serveCommand(f *Frontend, args []string) error {
+	var cfg Config
+	commandFlags("serve", "", &cfg).Parse(args)
+
+	if cfg.TraceFile != "" {
+		if err := DefaultTracer.ExportToFile(cfg.TraceFile); err != nil {
+			return err
+		}
+		defer DefaultTracer.Close()
+	}
+
+	if cfg.Precompress {
+		if err := compressAssets("./wwwroot"); err != nil {
+			return err
+		}
+	}
+
+	if err := loadAssets(); err != nil {
+		return err
+	}
+
+	app, reload, err := f.NewApp(&cfg, GetForecasts)
+	if err != nil {
+		return err
+	}
+	defer reloadOnHangup(reload)()
+
+	return app.Listen(cfg.Addr)
+}
+
+// The name of the function behind a handler, like cachePage or
+// (*site).index, leaving out the package and the .func1 Go gives closures.
+func handlerName(handler any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = name[strings.Index(name, ".")+1:]
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.Trim(name[i+len(".func"):], "0123456789.") != "" {
+			return name
+		}
+		name = name[:i]
+	}
+}
+
+func handlerNames(handlers []fiber.Handler) string {
+	names := make([]string, len(handlers))
+	for i, handler := range handlers {
+		names[i] = handlerName(handler)
+	}
+	return strings.Join(names, ", ")
+}
+
+func routesCommand(f *Frontend, args []string) error {
+	var cfg Config
+	commandFlags("routes", "", &cfg).Parse(args)
+	app, _, err := f.NewApp(&cfg, GetForecasts)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tTITLE\tHANDLERS")
+	for _, route := range app.GetRoutes(true) {
+		// Fiber answers HEAD for every GET route.
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		title, names := "", handlerNames(route.Handlers)
+		// The table's handlers are all wrapped by traced, so name them.
+		if r := findRoute(route.Method, route.Path); r != nil {
+			title = r.Title
+			names += "(" + handlerName(r.Handler) + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", route.Method, route.Path,
+			title, names)
+	}
+	return w.Flush()
+}
+
+func renderCommand(f *Frontend, args []string) error {
+	var cfg Config
+	fs := commandFlags("render", "<path>", &cfg)
+	boosted := fs.Bool("boosted", false,
+		"render the page the way hx-boost asks for it, without the layout")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	// Flags can follow the path too.
+	target := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+
+	if err := loadAssets(); err != nil {
+		return err
+	}
+	app, _, err := f.NewApp(&cfg, GetForecasts)
+	if err != nil {
+		return err
+	}
+	req := httptest.NewRequest(fiber.MethodGet, target, nil)
+	if *boosted {
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Boosted", "true")
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(os.Stdout, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		if location := resp.Header.Get("Location"); location != "" {
+			return fmt.Errorf("GET %s: %s to %s", target, resp.Status,
+				location)
+		}
+		return fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return nil
+}
+
+// Adds a user, with the password read from stdin, or makes an existing
+// user an admin.  Registering never makes anybody an admin, so this is how
+// a site gets its first one.
+func adduserCommand(f *Frontend, args []string) error {
+	var cfg Config
+	fs := commandFlags("adduser", "<name>", &cfg)
+	admin := fs.Bool("admin", false, "give the user the admin role")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name := fs.Arg(0)
+	if cfg.UsersFile == "" {
+		return fmt.Errorf("adduser needs -users; " +
+			"accounts kept in memory are gone when it exits")
+	}
+	users, err := NewUserStore(cfg.UsersFile)
+	if err != nil {
+		return err
+	}
+
+	if users.Get(name) == nil {
+		fmt.Fprintf(os.Stderr, "password for %s: ", name)
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			return err
+		}
+		_, err = users.Register(name, strings.TrimRight(password, "\r\n"))
+		if err != nil {
+			return err
+		}
+	} else if !*admin {
+		return ErrUserExists
+	}
+	if *admin {
+		return users.Grant(name, RoleAdmin)
+	}
+	return nil
+}
+
+func checkCommand(f *Frontend, args []string) error {
+	var cfg Config
+	commandFlags("check", "", &cfg).Parse(args)
+
+	// Whatever the check registers stays out of the real stores, in a
+	// users file of its own with an admin, who can see every page.
+	dir, err := os.MkdirTemp("", "likeBlazor-check-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	cfg.UsersFile = filepath.Join(dir, "users.json")
+	cfg.SessionStore = "memory"
+	cfg.RateLimit = false
+	users, err := NewUserStore(cfg.UsersFile)
+	if err != nil {
+		return err
+	}
+	if _, err := users.Register("check", "check-password"); err != nil {
+		return err
+	}
+	if err := users.Grant("check", RoleAdmin); err != nil {
+		return err
+	}
+	if err := loadAssets(); err != nil {
+		return err
+	}
+	app, _, err := f.NewApp(&cfg, GetForecasts)
+	if err != nil {
+		return err
+	}
+
+	send := func(method, target string, headers map[string]string,
+		cookies []*http.Cookie, body string) (*http.Response, error) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		resp, err := app.Test(req, -1)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+	resp, err := send(fiber.MethodPost, URLFor(RouteLogin), nil, nil,
+		url.Values{
+			"username": {"check"},
+			"password": {"check-password"},
+		}.Encode())
+	if err != nil {
+		return err
+	}
+	admin := resp.Cookies()
+
+	type render struct {
+		method, target, variant string
+		headers                 map[string]string
+		cookies                 []*http.Cookie
+		status                  int
+	}
+	boosted := map[string]string{"HX-Request": "true", "HX-Boosted": "true"}
+	fragment := map[string]string{"HX-Request": "true"}
+	renders := []render{
+		{"GET", "/check-missing-page", "page", nil, nil, 404},
+	}
+	for _, route := range app.GetRoutes(true) {
+		switch route.Method {
+		case fiber.MethodHead:
+		case fiber.MethodGet:
+			renders = append(renders,
+				render{route.Method, route.Path, "page", nil, admin, 200},
+				render{route.Method, route.Path, "boosted", boosted, admin,
+					200})
+		default:
+			// From a session of its own, so /logout doesn't end admin's.
+			renders = append(renders, render{route.Method, route.Path,
+				"fragment", fragment, nil, 0})
+		}
+	}
+
+	failed := 0
+	for _, r := range renders {
+		resp, err := send(r.method, r.target, r.headers, r.cookies, "")
+		if err != nil {
+			return err
+		}
+		if r.status == 0 && resp.StatusCode < 500 ||
+			resp.StatusCode == r.status {
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s %s (%s): %s\n", r.method, r.target, r.variant,
+			resp.Status)
+	}
+	fmt.Printf("rendered %d routes; %d failed\n", len(renders), failed)
+	if failed > 0 {
+		return fmt.Errorf("check failed")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	Addr       string
+	TraceFile  string
+	Production bool
+	CrashDir   string
+	RateLimit  bool
+
+	SessionStore string
+	SessionTTL   time.Duration
+	SessionKey   string
+
+	UsersFile string
+
+	Precompress bool
+
+	PageCacheTTL  time.Duration
+	PageCacheSize int
+
+	ForecastTTL time.Duration
+}
+
+func envOr(name, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return fallback
+}
+
+func envBool(name string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// Registers the app's flags on fs.  Environment variables supply the
+// defaults, so the apps can be configured either way.
+func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&cfg.Addr, "addr", envOr("ADDR", ":3000"),
+		"address to listen on")
+	fs.StringVar(&cfg.TraceFile, "trace", envOr("TRACE_FILE", ""),
+		"append spans as JSON lines to this file")
+	fs.BoolVar(&cfg.Production, "production", envBool("PRODUCTION", false),
+		"hide error details from visitors")
+	fs.StringVar(&cfg.CrashDir, "crash-dir", envOr("CRASH_DIR", ""),
+		"write a crash dump to this directory for every panic")
+	fs.BoolVar(&cfg.RateLimit, "rate-limit", envBool("RATE_LIMIT", true),
+		"rate limit polling and mutation endpoints")
+	fs.StringVar(&cfg.SessionStore, "sessions", envOr("SESSIONS", "memory"),
+		"where to keep sessions: memory, file:<directory> or bolt:<file>")
+	fs.DurationVar(&cfg.SessionTTL, "session-ttl",
+		envDuration("SESSION_TTL", 24*time.Hour),
+		"how long unused sessions last")
+	fs.StringVar(&cfg.SessionKey, "session-key", envOr("SESSION_KEY", ""),
+		"base64 AES key that encrypts cookies; random if empty")
+	fs.StringVar(&cfg.UsersFile, "users", envOr("USERS_FILE", ""),
+		"JSON file that keeps user accounts; in memory if empty")
+	fs.BoolVar(&cfg.Precompress, "precompress", envBool("PRECOMPRESS", true),
+		"write .br and .gz variants of wwwroot files at startup")
+	fs.DurationVar(&cfg.PageCacheTTL, "page-cache-ttl",
+		envDuration("PAGE_CACHE_TTL", time.Minute),
+		"how long to keep rendered pages; 0 turns the page cache off")
+	fs.IntVar(&cfg.PageCacheSize, "page-cache-size",
+		envInt("PAGE_CACHE_SIZE", 8<<20),
+		"most bytes of rendered pages to keep")
+	fs.DurationVar(&cfg.ForecastTTL, "forecast-ttl",
+		envDuration("FORECAST_TTL", 2*time.Second),
+		"how long all clients share the same forecasts; 0 computes them per request")
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Serves expvar's variables as JSON, like expvar.Handler, for benchmarks
+// that count allocations.  It leaves out cmdline, whose flags can hold
+// secrets like -session-key, so only memstats and whatever the app
+// publishes are left.
+func (s *site) debugVars(c *fiber.Ctx) error {
+	var b strings.Builder
+	b.WriteString("{\n")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" {
+			return
+		}
+		if !first {
+			b.WriteString(",\n")
+		}
+		first = false
+		fmt.Fprintf(&b, "%q: %s", kv.Key, kv.Value)
+	})
+	b.WriteString("\n}\n")
+	c.Type("json")
+	return c.SendString(b.String())
+}
@@ -0,0 +1,40 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"example/server/servertest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDebugVars(t *testing.T) {
+	app := servertest.NewApp(t, stub, servertest.WithAdmin(t, time.Now()))
+
+	resp := servertest.Request(t, app, fiber.MethodGet, "/debug/vars", nil,
+		nil, nil)
+	if resp.StatusCode == fiber.StatusOK {
+		t.Errorf("/debug/vars served to a visitor who isn't logged in")
+	}
+
+	resp = servertest.Request(t, app, fiber.MethodGet, "/debug/vars", nil,
+		nil, servertest.LogInAsAdmin(t, app))
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(body, &vars); err != nil {
+		t.Fatalf("%s: %v\n%s", resp.Status, err, body)
+	}
+	if vars["memstats"] == nil {
+		t.Errorf("no memstats in %s", body)
+	}
+	if vars["cmdline"] != nil {
+		t.Errorf("published the command line, with its secrets: %s",
+			vars["cmdline"])
+	}
+}
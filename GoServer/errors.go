@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// What the error page shows.
+type ErrorInfo struct {
+	Status    int
+	Title     string
+	Message   string
+	Detail    string
+	RequestID string
+}
+
+func errorStatus(err error) int {
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return fiberError.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+func errorMessage(code int) string {
+	switch {
+	case code == fiber.StatusNotFound:
+		return "Sorry, there's nothing at this address."
+	case code == fiber.StatusForbidden:
+		return "Sorry, you don't have access to this page."
+	case code >= 500:
+		return "Sorry, something went wrong on our end."
+	default:
+		return "Sorry, we couldn't handle that request."
+	}
+}
+
+// True for requests htmx makes on its own, like hx-get and hx-post, which
+// expect a fragment rather than a whole page.
+func isFragmentRequest(c *fiber.Ctx) bool {
+	return c.Get("HX-Request") == "true" && c.Get("HX-Boosted") != "true"
+}
+
+// Renders errors as a page inside the main layout, or as a fragment
+// retargeted at the main article for htmx requests.  In production the
+// error itself is only logged, never shown.
+func errorHandler(cfg *Config, views Views) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		code := errorStatus(err)
+		requestID, _ := c.Locals("requestid").(string)
+		if code >= 500 {
+			log.Printf("%s %s failed (request %s): %v",
+				c.Method(), c.OriginalURL(), requestID, err)
+		}
+
+		info := ErrorInfo{
+			Status:    code,
+			Title:     utils.StatusMessage(code),
+			Message:   errorMessage(code),
+			RequestID: requestID,
+		}
+		if !cfg.Production {
+			info.Detail = err.Error()
+		}
+
+		c.Status(code)
+		fragment := isFragmentRequest(c)
+		if fragment {
+			c.Set("HX-Retarget", "#main-article")
+			c.Set("HX-Reswap", "innerHTML")
+		}
+		renderErr := views.Error(c, info, fragment)
+		if renderErr != nil {
+			log.Printf("rendering error page (request %s): %v",
+				requestID, renderErr)
+			return c.Status(code).SendString(utils.StatusMessage(code))
+		}
+		return nil
+	}
+}
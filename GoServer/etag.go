@@ -0,0 +1,75 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The forms the CSP nonce takes in rendered pages: as it is, and with its
+// '+' written as "&#43;", the way html/template escapes it in attributes.
+func NonceForms(nonce string) [][]byte {
+	return [][]byte{
+		[]byte(nonce),
+		[]byte(strings.ReplaceAll(nonce, "+", "&#43;")),
+	}
+}
+
+// Pages are identical from one request to the next except for the CSP
+// nonce, so it's left out of the hash.
+func pageETag(body []byte, nonce string) string {
+	body = replaceNonce(body, NonceForms(nonce), [][]byte{nil, nil})
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// Whether an If-None-Match header lists etag, using the weak comparison
+// RFC 9110 calls for on GET and HEAD.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware that tags rendered pages with an ETag and answers a matching
+// If-None-Match with 304 Not Modified.  Pages that differ by HX-Boosted
+// have different bodies and so different tags, and the Vary header the
+// handler set is kept on the 304.
+//
+// A 304 leaves out the Content-Security-Policy, because the page the
+// browser already has was rendered with the nonce of the policy it
+// already has, not the fresh one.
+func conditionalGet(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		return err
+	}
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead ||
+		c.Response().StatusCode() != fiber.StatusOK ||
+		c.Response().IsBodyStream() ||
+		len(c.Response().Header.Peek(fiber.HeaderETag)) > 0 {
+		return nil
+	}
+
+	nonce, _ := c.Locals("cspNonce").(string)
+	etag := pageETag(c.Response().Body(), nonce)
+	c.Set(fiber.HeaderETag, etag)
+	if len(c.Response().Header.Peek(fiber.HeaderCacheControl)) == 0 {
+		// Pages show who is logged in, so only the browser may keep them,
+		// and it has to check back before using them.
+		c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	}
+	if !etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return nil
+	}
+	c.Response().Header.Del("Content-Security-Policy")
+	c.Response().ResetBody()
+	c.Status(fiber.StatusNotModified)
+	return nil
+}
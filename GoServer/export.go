@@ -0,0 +1,277 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/html"
+)
+
+// The pages that render the same for every visitor who isn't logged in,
+// so they can be served as files: the ones the page cache keeps.
+func staticPages() []string {
+	var pages []string
+	for _, route := range routes {
+		if route.Method == fiber.MethodGet && route.Cached {
+			pages = append(pages, route.Path)
+		}
+	}
+	return pages
+}
+
+// The file a page is exported to.  Static hosts serve /about from
+// about.html.
+func exportFile(page string) string {
+	if page == "/" {
+		return "index.html"
+	}
+	return strings.TrimPrefix(page, "/") + ".html"
+}
+
+// What an export wrote and what it couldn't.
+type ExportReport struct {
+	Pages   []string
+	Boosted []string
+	Assets  int
+	// The routes a static host can't serve, like "POST /forecasts".
+	Dynamic []string
+	// For each exported page, the dynamic routes it links to or calls.
+	Needs map[string][]string
+}
+
+// Renders the static pages to dir, boosted variants under dir/_boosted,
+// and the 404 page to dir/404.html, and copies the files under wwwroot
+// to dir under both their names and their fingerprinted URLs.
+func exportSite(app *fiber.App, dir, wwwroot string,
+	manifest *AssetManifest) (*ExportReport, error) {
+	report := &ExportReport{Needs: make(map[string][]string)}
+
+	staticPaths := staticPages()
+	dynamic := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Method == "USE" {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		static := route.Method == fiber.MethodGet &&
+			slices.Contains(staticPaths, route.Path)
+		if !static && !dynamic[key] {
+			dynamic[key] = true
+			report.Dynamic = append(report.Dynamic, key)
+		}
+	}
+	sort.Strings(report.Dynamic)
+
+	type variant struct {
+		file    string
+		headers map[string]string
+		status  int
+	}
+	pages := append(staticPaths, "/404")
+	for _, page := range pages {
+		variants := []variant{
+			{exportFile(page), nil, fiber.StatusOK},
+			{path.Join("_boosted", exportFile(page)), map[string]string{
+				"HX-Request": "true", "HX-Boosted": "true"}, fiber.StatusOK},
+		}
+		if page == "/404" {
+			variants = variants[:1]
+			variants[0].status = fiber.StatusNotFound
+		}
+		for _, v := range variants {
+			body, err := renderRoute(app, page, v.headers, v.status)
+			if err != nil {
+				return nil, err
+			}
+			if err := writeFile(filepath.Join(dir, v.file), body); err != nil {
+				return nil, err
+			}
+			if v.headers == nil {
+				report.Pages = append(report.Pages, v.file)
+				report.Needs[v.file] = dynamicTargets(body, dynamic)
+			} else {
+				report.Boosted = append(report.Boosted, v.file)
+			}
+		}
+	}
+
+	copied, err := copyAssets(wwwroot, dir, manifest)
+	report.Assets = copied
+	return report, err
+}
+
+// Renders a route as a visitor who isn't logged in.
+func renderRoute(app *fiber.App, target string, headers map[string]string,
+	status int) ([]byte, error) {
+	req := httptest.NewRequest(fiber.MethodGet, target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		return nil, fmt.Errorf("GET %s: got %s, want %d", target,
+			resp.Status, status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// The dynamic routes a page's links, forms and hx-* attributes use.
+func dynamicTargets(page []byte, dynamic map[string]bool) []string {
+	found := make(map[string]bool)
+	z := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tag, _ := z.TagName()
+		attrs := make(map[string]string)
+		for more := true; more; {
+			var key, value []byte
+			key, value, more = z.TagAttr()
+			attrs[string(key)] = string(value)
+		}
+		var uses []string
+		switch string(tag) {
+		case "a":
+			if href, ok := attrs["href"]; ok {
+				uses = append(uses, fiber.MethodGet+" "+href)
+			}
+		case "form":
+			method := strings.ToUpper(attrs["method"])
+			if method == "" {
+				method = fiber.MethodGet
+			}
+			uses = append(uses, method+" "+attrs["action"])
+		}
+		for _, verb := range []string{"get", "post", "put", "patch",
+			"delete"} {
+			if target, ok := attrs["hx-"+verb]; ok {
+				uses = append(uses, strings.ToUpper(verb)+" "+target)
+			}
+		}
+		for _, use := range uses {
+			use, _, _ = strings.Cut(use, "?")
+			if dynamic[use] {
+				found[use] = true
+			}
+		}
+	}
+	targets := make([]string, 0, len(found))
+	for target := range found {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// Copies the files under wwwroot, along with their precompressed
+// variants, to dir, and copies the files the manifest fingerprints to
+// their fingerprinted names too.  Returns how many files it wrote.
+func copyAssets(wwwroot, dir string, manifest *AssetManifest) (int, error) {
+	copied := 0
+	err := filepath.WalkDir(wwwroot, func(file string, d fs.DirEntry,
+		err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+		rel, err := filepath.Rel(wwwroot, file)
+		if err != nil {
+			return err
+		}
+		copied++
+		return copyFile(file, filepath.Join(dir, rel))
+	})
+	if err != nil {
+		return copied, err
+	}
+	for url, name := range manifest.files {
+		err := copyFile(filepath.Join(wwwroot, filepath.FromSlash(name)),
+			filepath.Join(dir, filepath.FromSlash(url)))
+		if err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+func copyFile(from, to string) error {
+	content, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return writeFile(to, content)
+}
+
+func writeFile(name string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, content, 0o644)
+}
+
+// Writes the report in Markdown.
+func (r *ExportReport) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Export\n\n")
+	fmt.Fprintf(&b, "Wrote %d pages, %d boosted variants and %d asset "+
+		"files.\n\n", len(r.Pages), len(r.Boosted), r.Assets)
+	fmt.Fprintf(&b, "htmx asks for boosted pages at the same URLs as full "+
+		"ones, with an HX-Boosted: true header.  Hosts that can route on "+
+		"headers should serve _boosted/ for those requests; others serve "+
+		"full pages, which htmx swaps in whole.\n\n")
+	fmt.Fprintf(&b, "## Dynamic routes\n\n")
+	fmt.Fprintf(&b, "A static host can't serve these.  Proxy them to the "+
+		"app, or expect them to fail.\n\n")
+	for _, route := range r.Dynamic {
+		fmt.Fprintf(&b, "- `%s`\n", route)
+	}
+	fmt.Fprintf(&b, "\n## Pages that use dynamic routes\n\n")
+	for _, page := range r.Pages {
+		if needs := r.Needs[page]; len(needs) > 0 {
+			fmt.Fprintf(&b, "- %s: `%s`\n", page,
+				strings.Join(needs, "`, `"))
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func exportCommand(f *Frontend, args []string) error {
+	var cfg Config
+	fs := commandFlags("export", "", &cfg)
+	out := fs.String("out", "export", "directory to export the site to")
+	fs.Parse(args)
+
+	if err := loadAssets(); err != nil {
+		return err
+	}
+	app, _, err := f.NewApp(&cfg, GetForecasts)
+	if err != nil {
+		return err
+	}
+	report, err := exportSite(app, *out, "./wwwroot", assets)
+	if err != nil {
+		return err
+	}
+	_, err = report.WriteTo(os.Stdout)
+	return err
+}
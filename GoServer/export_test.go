@@ -0,0 +1,57 @@
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"example/server"
+	"example/server/servertest"
+)
+
+// A stand-in for an app's wwwroot.
+const wwwroot = "testdata/wwwroot"
+
+func TestExportSite(t *testing.T) {
+	manifest, err := server.LoadAssetManifest(wwwroot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	report, err := server.ExportSite(servertest.NewApp(t, stub), dir, wwwroot,
+		manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"index.html", "about.html", "fetchdata.html",
+		"404.html", "_boosted/about.html", "css/BlazorApp.styles.css",
+		strings.TrimPrefix(manifest.URL("/css/BlazorApp.styles.css"), "/")} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Error(err)
+		}
+	}
+	boosted, err := os.ReadFile(filepath.Join(dir, "_boosted", "about.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(boosted), "<html") {
+		t.Error("_boosted/about.html is a whole page")
+	}
+
+	for _, route := range []string{"GET /counter", "POST /forecasts",
+		"POST /logout"} {
+		if !slices.Contains(report.Dynamic, route) {
+			t.Errorf("%s isn't listed as dynamic: %v", route, report.Dynamic)
+		}
+	}
+	if slices.Contains(report.Dynamic, "GET /about") {
+		t.Error("GET /about is listed as dynamic")
+	}
+	if needs := report.Needs["fetchdata.html"]; !slices.Contains(needs,
+		"POST /forecasts") {
+		t.Errorf("fetchdata.html needs %v, want POST /forecasts", needs)
+	}
+}
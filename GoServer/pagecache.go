@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Rendered pages, kept for a while so pages that come out the same every
+// time aren't rendered every time.  The least recently used pages are
+// dropped to keep the total size under a limit.
+type PageCache struct {
+	ttl     time.Duration
+	maxSize int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	size    int
+	stats   PageCacheStats
+}
+
+type PageCacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Bytes     int    `json:"bytes"`
+}
+
+type cachedPage struct {
+	key         string
+	body        []byte
+	contentType string
+	vary        string
+	expires     time.Time
+}
+
+// A ttl of zero turns caching off.
+func NewPageCache(ttl time.Duration, maxSize int) *PageCache {
+	return &PageCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (pc *PageCache) get(key string, now time.Time) *cachedPage {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	element := pc.entries[key]
+	if element == nil {
+		pc.stats.Misses++
+		return nil
+	}
+	page := element.Value.(*cachedPage)
+	if now.After(page.expires) {
+		pc.remove(element)
+		pc.stats.Misses++
+		return nil
+	}
+	pc.lru.MoveToFront(element)
+	pc.stats.Hits++
+	return page
+}
+
+func (pc *PageCache) put(page *cachedPage) {
+	if len(page.body) > pc.maxSize {
+		return
+	}
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	if element := pc.entries[page.key]; element != nil {
+		pc.remove(element)
+	}
+	pc.entries[page.key] = pc.lru.PushFront(page)
+	pc.size += len(page.body)
+	for pc.size > pc.maxSize {
+		pc.remove(pc.lru.Back())
+		pc.stats.Evictions++
+	}
+}
+
+// Callers hold the lock.
+func (pc *PageCache) remove(element *list.Element) {
+	page := pc.lru.Remove(element).(*cachedPage)
+	delete(pc.entries, page.key)
+	pc.size -= len(page.body)
+}
+
+// Drops every page, as when the templates they came from change.
+func (pc *PageCache) Purge() {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	pc.entries = make(map[string]*list.Element)
+	pc.lru.Init()
+	pc.size = 0
+}
+
+func (pc *PageCache) Stats() PageCacheStats {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	stats := pc.stats
+	stats.Entries = len(pc.entries)
+	stats.Bytes = pc.size
+	return stats
+}
+
+// Pages differ by whether htmx boosted the request, which replaces only
+// the layout's contents, and by who's logged in, who the nav menu shows.
+func pageCacheKey(c *fiber.Ctx) string {
+	return c.OriginalURL() +
+		"\x00" + c.Get("HX-Request") +
+		"\x00" + c.Get("HX-Boosted") +
+		"\x00" + CurrentSession(c).Username()
+}
+
+// Stands in for the CSP nonce in cached pages, so each request that gets a
+// cached page gets it with its own nonce.
+var noncePlaceholders = [][]byte{[]byte("\x00nonce\x00"), []byte("\x00nonce-html\x00")}
+
+func replaceNonce(body []byte, old, new [][]byte) []byte {
+	for i := range old {
+		if len(old[i]) == 0 {
+			continue
+		}
+		body = bytes.ReplaceAll(body, old[i], new[i])
+	}
+	return body
+}
+
+// Handler that serves a route's pages from the cache, or caches what the
+// route renders.  Only successful GETs are cached.
+func cachePage(pc *PageCache) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if pc.ttl <= 0 || c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+		key := pageCacheKey(c)
+		nonce, _ := c.Locals("cspNonce").(string)
+		span := SpanFromContext(c.UserContext())
+		if page := pc.get(key, time.Now()); page != nil {
+			span.SetAttribute("pageCache", "hit")
+			c.Set("X-Cache", "HIT")
+			if page.vary != "" {
+				c.Set(fiber.HeaderVary, page.vary)
+			}
+			c.Set(fiber.HeaderContentType, page.contentType)
+			return c.Send(replaceNonce(page.body, noncePlaceholders,
+				NonceForms(nonce)))
+		}
+		span.SetAttribute("pageCache", "miss")
+		c.Set("X-Cache", "MISS")
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() != fiber.StatusOK ||
+			c.Response().IsBodyStream() {
+			return nil
+		}
+		pc.put(&cachedPage{
+			key: key,
+			body: replaceNonce(bytes.Clone(c.Response().Body()),
+				NonceForms(nonce), noncePlaceholders),
+			contentType: string(c.Response().Header.ContentType()),
+			vary:        string(c.Response().Header.Peek(fiber.HeaderVary)),
+			expires:     time.Now().Add(pc.ttl),
+		})
+		return nil
+	}
+}
+
+// Reports the page cache's hit and miss counts as JSON.
+func (s *site) cacheStats(c *fiber.Ctx) error {
+	return c.JSON(s.pages.Stats())
+}
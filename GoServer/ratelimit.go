@@ -0,0 +1,122 @@
+package server
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// A token bucket rate limit: Burst requests at once, refilled at Rate
+// requests per second.
+type RatePolicy struct {
+	Rate  float64
+	Burst float64
+}
+
+// Every open FetchData tab polls /forecasts every 2 seconds, so this
+// allows a handful of tabs per client.
+var forecastsPolicy = RatePolicy{Rate: 2, Burst: 10}
+
+var incrementPolicy = RatePolicy{Rate: 5, Burst: 20}
+
+// Slows down password guessing.
+var loginPolicy = RatePolicy{Rate: 0.2, Burst: 5}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type RateLimiter struct {
+	policy    RatePolicy
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// Buckets idle this long are full again, so they are dropped.
+const bucketIdleTime = 10 * time.Minute
+
+func NewRateLimiter(policy RatePolicy) *RateLimiter {
+	return &RateLimiter{
+		policy:    policy,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Takes a token from key's bucket.  When there are none left, returns false
+// and how long until there will be one.
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if now.Sub(l.lastSweep) > bucketIdleTime {
+		for k, bucket := range l.buckets {
+			if now.Sub(bucket.last) > bucketIdleTime {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket := l.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: l.policy.Burst, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.policy.Burst,
+		bucket.tokens+now.Sub(bucket.last).Seconds()*l.policy.Rate)
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := (1 - bucket.tokens) / l.policy.Rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// Identifies the client a request counts against: the logged in user, so
+// users behind one NAT or proxy get buckets of their own, and otherwise
+// the IP address.  Anonymous sessions don't get their own, since a client
+// can start as many as it likes.
+//
+// c.IP() is the address of whoever connected, which behind a reverse
+// proxy is the proxy, so everyone shares one bucket.  Setting fiber's
+// ProxyHeader, like X-Forwarded-For, makes it the header's address
+// instead; only do that with EnableTrustedProxyCheck and TrustedProxies
+// set to the proxies, or clients pick their own address.
+func rateLimitKey(c *fiber.Ctx) string {
+	if session := CurrentSession(c); session != nil &&
+		session.Username() != "" {
+		return "user:" + session.Username()
+	}
+	return "ip:" + c.IP()
+}
+
+// Handler that rate limits a route.  Limited htmx requests get a fragment
+// that retries the same request once the client may make it again, which
+// slows polling down instead of breaking the page.  Other requests get the
+// 429 error page.
+func rateLimit(cfg *Config, views Views, policy RatePolicy) fiber.Handler {
+	limiter := NewRateLimiter(policy)
+	return func(c *fiber.Ctx) error {
+		if !cfg.RateLimit {
+			return c.Next()
+		}
+		allowed, wait := limiter.Allow(rateLimitKey(c), time.Now())
+		if allowed {
+			return c.Next()
+		}
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		if !isFragmentRequest(c) {
+			return fiber.ErrTooManyRequests
+		}
+		c.Status(fiber.StatusTooManyRequests)
+		return views.TooManyRequests(c, c.Method(), c.OriginalURL(),
+			retryAfter)
+	}
+}
@@ -0,0 +1,45 @@
+package server_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"example/server"
+	"example/server/servertest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Logged in users share an address but not a bucket.
+func TestRateLimitPerUser(t *testing.T) {
+	app := servertest.NewApp(t, stub, "-rate-limit=true")
+	logIn := func(name string) []*http.Cookie {
+		form := url.Values{"username": {name}, "password": {"password1"},
+			"confirm": {"password1"}}
+		resp := servertest.Request(t, app, fiber.MethodPost, "/register",
+			strings.NewReader(form.Encode()), nil, nil)
+		if resp.StatusCode != fiber.StatusSeeOther {
+			t.Fatalf("registering %s: got %s", name, resp.Status)
+		}
+		return resp.Cookies()
+	}
+	ada, grace := logIn("ada"), logIn("grace")
+
+	increment := func(cookies []*http.Cookie) int {
+		return servertest.Request(t, app, fiber.MethodGet, "/increment?count=1", nil,
+			servertest.Fragment, cookies).StatusCode
+	}
+	for i := 0; i < int(server.IncrementPolicy.Burst); i++ {
+		if status := increment(ada); status != fiber.StatusOK {
+			t.Fatalf("request %d: got %d", i+1, status)
+		}
+	}
+	if status := increment(ada); status != fiber.StatusTooManyRequests {
+		t.Errorf("ada after the burst: got %d, want 429", status)
+	}
+	if status := increment(grace); status != fiber.StatusOK {
+		t.Errorf("grace after ada's burst: got %d, want 200", status)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// Everything we know about a panic, as logged and as written to crash
+// dumps.
+type CrashReport struct {
+	Time      time.Time         `json:"time"`
+	Panic     string            `json:"panic"`
+	RequestID string            `json:"requestId,omitempty"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Route     string            `json:"route"`
+	Htmx      map[string]string `json:"htmx,omitempty"`
+	Stack     string            `json:"stack"`
+}
+
+var htmxRequestHeaders = []string{
+	"HX-Request", "HX-Boosted", "HX-Current-URL", "HX-History-Restore-Request",
+	"HX-Prompt", "HX-Target", "HX-Trigger", "HX-Trigger-Name",
+}
+
+var crashLog = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+func newCrashReport(c *fiber.Ctx, recovered interface{}, stack []byte) CrashReport {
+	report := CrashReport{
+		Time:   time.Now(),
+		Panic:  fmt.Sprint(recovered),
+		Method: c.Method(),
+		URL:    c.OriginalURL(),
+		Route:  c.Route().Path,
+		Stack:  string(stack),
+	}
+	report.RequestID, _ = c.Locals("requestid").(string)
+	for _, header := range htmxRequestHeaders {
+		if value := c.Get(header); value != "" {
+			if report.Htmx == nil {
+				report.Htmx = make(map[string]string)
+			}
+			report.Htmx[header] = value
+		}
+	}
+	return report
+}
+
+// Writes the report to a new file in dir, and returns the file's path.
+// The name is made only of the time and a random suffix: request IDs come
+// from clients' X-Request-ID headers, so they stay inside the file.
+func (report CrashReport) SaveIn(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	var suffix [4]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return "", err
+	}
+	name := "crash-" + report.Time.Format("20060102-150405.000") + "-" +
+		hex.EncodeToString(suffix[:])
+	path := filepath.Join(dir, name+".json")
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// Middleware that turns panics into errors, so the error handler renders
+// the error page, after logging what was going on and optionally saving
+// a crash dump to cfg.CrashDir.  It goes before every other middleware,
+// so it catches their panics too.
+func recoverPanics(cfg *Config) fiber.Handler {
+	return recover.New(recover.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c *fiber.Ctx, recovered interface{}) {
+			report := newCrashReport(c, recovered, debug.Stack())
+			crashLog.Error("panic",
+				"panic", report.Panic,
+				"requestId", report.RequestID,
+				"method", report.Method,
+				"url", report.URL,
+				"route", report.Route,
+				"htmx", report.Htmx,
+				"stack", report.Stack)
+			if cfg.CrashDir == "" {
+				return
+			}
+			if path, err := report.SaveIn(cfg.CrashDir); err != nil {
+				crashLog.Error("writing crash dump failed",
+					"requestId", report.RequestID, "error", err.Error())
+			} else {
+				crashLog.Info("wrote crash dump",
+					"requestId", report.RequestID, "path", path)
+			}
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// Request IDs come from clients, so they mustn't pick where dumps go.
+func TestCrashDumpIgnoresRequestIDPaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b", "crashes")
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(recoverPanics(&Config{CrashDir: dir}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		panic("oops")
+	})
+
+	id := "../../../escaped/x"
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderXRequestID, id)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var dumps []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			dumps = append(dumps, path)
+		}
+		return err
+	})
+	if len(dumps) != 1 || filepath.Dir(dumps[0]) != dir {
+		t.Fatalf("got dumps %v, want one in %s", dumps, dir)
+	}
+	data, err := os.ReadFile(dumps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report CrashReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.RequestID != id {
+		t.Errorf("got request ID %q, want %q", report.RequestID, id)
+	}
+}
@@ -0,0 +1,26 @@
+package server_test
+
+import (
+	"testing"
+
+	"example/server"
+	"example/server/servertest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Apps' own middleware runs before most of the server's, so its panics
+// must be recovered too.
+func TestRecoverAppMiddleware(t *testing.T) {
+	app := servertest.NewApp(t, &server.Frontend{
+		Views: server.StubViews{},
+		Middleware: []fiber.Handler{func(c *fiber.Ctx) error {
+			panic("oops")
+		}},
+	})
+
+	resp := servertest.Request(t, app, fiber.MethodGet, "/", nil, nil, nil)
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("got %s, want 500", resp.Status)
+	}
+}
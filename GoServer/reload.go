@@ -0,0 +1,23 @@
+package server
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Calls reload whenever the process gets a SIGHUP, as from kill -HUP, until
+// the returned func stops it.
+func reloadOnHangup(reload func()) (stop func()) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			reload()
+		}
+	}()
+	return func() {
+		signal.Stop(hangups)
+		close(hangups)
+	}
+}
@@ -0,0 +1,449 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Who the nav menu shows a route's item to.
+type NavVisibility int
+
+const (
+	// Nobody: the route isn't in the nav.
+	NavHidden NavVisibility = iota
+	// Visitors allowed to request the route.
+	NavShown
+	// Visitors who aren't logged in, like Log in.
+	NavLoggedOut
+)
+
+// What a route answers with.
+type ResponseKind int
+
+const (
+	// A page, or with HX-Boosted the main layout without the html around
+	// it.
+	PageResponse ResponseKind = iota
+	// A fragment that htmx swaps into the page.
+	FragmentResponse
+	// A redirect, or for htmx an HX-Redirect.
+	RedirectResponse
+	// JSON, for tools rather than visitors.
+	JSONResponse
+)
+
+// A route's name, for building URLs to it with RouteURL and URLFor.
+type RouteName string
+
+const (
+	RouteHome      RouteName = "home"
+	RouteAbout     RouteName = "about"
+	RouteCounter   RouteName = "counter"
+	RouteIncrement RouteName = "increment"
+	RouteFetchData RouteName = "fetchdata"
+	RouteForecasts RouteName = "forecasts"
+	RouteLogin     RouteName = "login"
+	RouteRegister  RouteName = "register"
+	RouteLogout    RouteName = "logout"
+	RouteAdmin     RouteName = "admin"
+	RouteCache     RouteName = "cache"
+	RouteUsers     RouteName = "users"
+	RouteDebugVars RouteName = "debugvars"
+)
+
+// A route: what handles it, and what the nav menu, page titles and
+// authorization need to know about it.
+type Route struct {
+	// What URLs to the route are built from.  A path's other routes, like
+	// POST /login beside GET /login, go without.
+	Name   RouteName
+	Method string
+	Path   string
+	// The query parameters the route reads, the only ones its URLs may
+	// have besides the path's.
+	Query []string
+	// The title of the pages the route renders.
+	Title string
+	// The nav item's text, and the open-iconic icon beside it.
+	NavText string
+	Icon    string
+	Nav     NavVisibility
+	// Where the nav item goes among its siblings; lower comes first.
+	NavOrder int
+	// The path of the route whose sub-menu the nav item is in, and which
+	// comes before it in the breadcrumb.  Routes without one are under
+	// Home.
+	Parent string
+	// The roles visitors need at least one of.  Routes that need none are
+	// public.  All of a path's routes need the same roles.
+	Roles []string
+	// Whether the page cache keeps the route's pages.
+	Cached bool
+	// What the route answers with; a page unless set.
+	Response ResponseKind
+	// How often a visitor may request the route, if that's limited.
+	Limit   *RatePolicy
+	Handler func(s *site, c *fiber.Ctx) error
+}
+
+// What handlers share.
+type site struct {
+	cfg     *Config
+	views   Views
+	users   *UserStore
+	pages   *PageCache
+	weather *ForecastCache
+}
+
+// Every route, in the order they're registered.  Set in init, because
+// handlers render the nav menu, which reads the table.
+var routes []Route
+
+func init() {
+	routes = []Route{
+		{Name: RouteHome, Method: "GET", Path: "/", Title: "Home",
+			NavText: "Home", Icon: "home", Nav: NavShown, NavOrder: 1,
+			Cached: true, Handler: (*site).index},
+		{Name: RouteAbout, Method: "GET", Path: "/about", Title: "About",
+			Cached: true, Handler: (*site).about},
+		{Name: RouteCounter, Method: "GET", Path: "/counter",
+			Title: "Counter", NavText: "Counter", Icon: "plus",
+			Nav: NavShown, NavOrder: 2, Roles: []string{RoleMember},
+			Handler: (*site).counter},
+		{Name: RouteIncrement, Method: "GET", Path: "/increment",
+			Query: []string{"count"}, Roles: []string{RoleMember},
+			Response: FragmentResponse, Limit: &incrementPolicy,
+			Handler: (*site).increment},
+		{Name: RouteFetchData, Method: "GET", Path: "/fetchdata",
+			Title: "Weather forecast", NavText: "Fetch data",
+			Icon: "list-rich", Nav: NavShown, NavOrder: 3, Cached: true,
+			Handler: (*site).fetchData},
+		{Name: RouteForecasts, Method: "POST", Path: "/forecasts",
+			Response: FragmentResponse, Limit: &forecastsPolicy,
+			Handler: (*site).forecasts},
+		{Name: RouteLogin, Method: "GET", Path: "/login",
+			Query: []string{"next"}, Title: "Log in", NavText: "Log in",
+			Icon: "account-login", Nav: NavLoggedOut, NavOrder: 5,
+			Handler: (*site).showLogin},
+		{Method: "POST", Path: "/login", Title: "Log in",
+			Limit: &loginPolicy, Handler: (*site).login},
+		{Name: RouteRegister, Method: "GET", Path: "/register",
+			Query: []string{"next"}, Title: "Register",
+			Handler: (*site).showRegister},
+		{Method: "POST", Path: "/register", Title: "Register",
+			Limit: &loginPolicy, Handler: (*site).register},
+		{Name: RouteLogout, Method: "POST", Path: "/logout",
+			Response: RedirectResponse, Handler: (*site).logout},
+		{Name: RouteAdmin, Method: "GET", Path: "/admin", Title: "Admin",
+			NavText: "Admin", Icon: "cog", Nav: NavShown, NavOrder: 4,
+			Roles: []string{RoleAdmin}, Handler: (*site).adminPage},
+		{Name: RouteCache, Method: "GET", Path: "/admin/cache",
+			Title: "Page cache", Parent: "/admin",
+			Roles: []string{RoleAdmin}, Response: JSONResponse,
+			Handler: (*site).cacheStats},
+		{Name: RouteUsers, Method: "GET", Path: "/admin/users",
+			Title: "Users", NavText: "Users", Icon: "people", Nav: NavShown,
+			NavOrder: 1, Parent: "/admin", Roles: []string{RoleAdmin},
+			Handler: (*site).usersPage},
+		{Name: RouteDebugVars, Method: "GET", Path: "/debug/vars",
+			Title: "Debug vars", Parent: "/admin",
+			Roles: []string{RoleAdmin}, Response: JSONResponse,
+			Handler: (*site).debugVars},
+	}
+}
+
+// Registers every route in the table on app.
+func (s *site) addRoutes(app *fiber.App) {
+	for _, route := range routes {
+		app.Add(route.Method, route.Path, s.handlers(route)...)
+	}
+}
+
+// The route's middleware and handler.
+func (s *site) handlers(route Route) []fiber.Handler {
+	var handlers []fiber.Handler
+	if route.Limit != nil {
+		handlers = append(handlers,
+			rateLimit(s.cfg, s.views, *route.Limit))
+	}
+	if route.Cached {
+		handlers = append(handlers, cachePage(s.pages))
+	}
+	handler := route.Handler
+	return append(handlers, traced(func(c *fiber.Ctx) error {
+		return handler(s, c)
+	}))
+}
+
+// The table's route for method and path, or nil.
+func findRoute(method, path string) *Route {
+	for i := range routes {
+		if routes[i].Method == method && routes[i].Path == path {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// The table's route named name, or nil.
+func namedRoute(name RouteName) *Route {
+	for i := range routes {
+		if routes[i].Name == name {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// The URL of the route named name, filling in the path's parameters, like
+// :id, and adding the rest to the query.  params are names and values, in
+// turns.  Names the route doesn't have are errors.
+func RouteURL(name RouteName, params ...any) (string, error) {
+	route := namedRoute(name)
+	if route == nil {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("route %s: parameter %v has no value", name,
+			params[len(params)-1])
+	}
+	values := make(map[string]string)
+	var keys []string
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("route %s: parameter name %v isn't a string",
+				name, params[i])
+		}
+		values[key] = fmt.Sprint(params[i+1])
+		keys = append(keys, key)
+	}
+
+	segments := strings.Split(route.Path, "/")
+	for i, segment := range segments {
+		if key, ok := strings.CutPrefix(segment, ":"); ok {
+			value, ok := values[key]
+			if !ok {
+				return "", fmt.Errorf("route %s needs parameter %s", name, key)
+			}
+			segments[i] = url.PathEscape(value)
+			delete(values, key)
+		}
+	}
+	query := make(url.Values)
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			if !slices.Contains(route.Query, key) {
+				return "", fmt.Errorf("route %s has no parameter %s", name,
+					key)
+			}
+			query.Set(key, value)
+		}
+	}
+	u := strings.Join(segments, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u, nil
+}
+
+// Like RouteURL, for code that can't return errors, like templ
+// attributes.  Bad parameters are bugs, so it panics on them.
+func URLFor(name RouteName, params ...any) string {
+	u, err := RouteURL(name, params...)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// The title of the page c's route renders.
+func RouteTitle(c *fiber.Ctx) string {
+	if route := findRoute(c.Route().Method, c.Route().Path); route != nil {
+		return route.Title
+	}
+	return ""
+}
+
+// The roles path needs.
+func routeRoles(path string) []string {
+	key := permissionKey(path)
+	for _, route := range routes {
+		if permissionKey(route.Path) == key {
+			return route.Roles
+		}
+	}
+	return nil
+}
+
+// A link in the nav menu, and the sub-menu under it.
+type NavItem struct {
+	Text   string
+	Path   string
+	Icon   string
+	Active bool
+	Items  []NavItem
+}
+
+// The id of the checkbox that opens the item's sub-menu, like
+// submenu-admin.
+func (item NavItem) MenuID() string {
+	return "submenu" + strings.ReplaceAll(item.Path, "/", "-")
+}
+
+// Whether the page at path is under the route at parent.  Home is only
+// above the pages that aren't under anything else, so it matches just
+// itself.
+func underPath(path, parent string) bool {
+	return path == parent || parent != "/" &&
+		strings.HasPrefix(path, parent+"/")
+}
+
+// The nav menu's links for a visitor with access, on the page at path.
+// Sub-menus hold the routes under their item's route, and items are
+// active when path is their route's or under it.
+func NavItems(access Access, path string) []NavItem {
+	return navChildren(access, path, "")
+}
+
+func navChildren(access Access, path, parent string) []NavItem {
+	var nav []Route
+	for _, route := range routes {
+		switch {
+		case route.Method != fiber.MethodGet || route.Nav == NavHidden:
+		case route.Parent != parent:
+		case route.Nav == NavLoggedOut && access.User != nil:
+		case !access.Can(route.Path):
+		default:
+			nav = append(nav, route)
+		}
+	}
+	sort.SliceStable(nav, func(i, j int) bool {
+		return nav[i].NavOrder < nav[j].NavOrder
+	})
+	items := make([]NavItem, len(nav))
+	for i, route := range nav {
+		items[i] = NavItem{route.NavText, route.Path, route.Icon,
+			underPath(path, route.Path),
+			navChildren(access, path, route.Path)}
+	}
+	return items
+}
+
+// The pages under parent that a visitor with access may see, nav menu
+// or not.
+func subpages(access Access, parent string) []Route {
+	var pages []Route
+	for _, route := range routes {
+		if route.Method == fiber.MethodGet && route.Parent == parent &&
+			route.Title != "" && access.Can(route.Path) {
+			pages = append(pages, route)
+		}
+	}
+	return pages
+}
+
+// A step in the breadcrumb.  The last one, the current page, has no
+// path.
+type Crumb struct {
+	Text string
+	Path string
+}
+
+// The trail from Home down to the page at path, or nothing for Home and
+// the pages that aren't in the route table.
+func Breadcrumbs(path string) []Crumb {
+	route := findRoute(fiber.MethodGet, path)
+	if route == nil || route.Path == "/" {
+		return nil
+	}
+	crumbs := []Crumb{{Text: route.Title}}
+	for route.Parent != "" {
+		if route = findRoute(fiber.MethodGet, route.Parent); route == nil {
+			break
+		}
+		crumbs = append(crumbs, Crumb{route.Title, route.Path})
+	}
+	if home := findRoute(fiber.MethodGet, "/"); home != nil {
+		crumbs = append(crumbs, Crumb{home.Title, home.Path})
+	}
+	slices.Reverse(crumbs)
+	return crumbs
+}
+
+// Every route, in the order they're registered.
+func Routes() []Route {
+	return slices.Clone(routes)
+}
+
+// How a route gets requested: as is, with HX-Boosted, or like htmx does
+// for a fragment.
+type Variant string
+
+const (
+	PageVariant     Variant = "page"
+	BoostedVariant  Variant = "boosted"
+	FragmentVariant Variant = "fragment"
+)
+
+// A request for a route, one of those RouteRequests lists.
+type RouteRequest struct {
+	// Like login.post.boosted: the route's name, or its path's and its
+	// method, then the variant unless it's the only one.
+	Name    string
+	Method  string
+	Target  string
+	Variant Variant
+	Route   Route
+	// The status the request gets from a visitor the route allows.
+	Status int
+}
+
+// Values for query parameters in RouteRequests' targets.
+var sampleQuery = map[string]string{"count": "3", "next": "/counter"}
+
+// Every way each route gets requested, in the table's order, for tests
+// and tools that go through them all: pages whole and boosted, fragments
+// the way htmx asks for them, redirects both ways, and JSON as is.
+func RouteRequests() []RouteRequest {
+	var requests []RouteRequest
+	for _, route := range routes {
+		name := string(route.Name)
+		if name == "" {
+			if named := findRoute(fiber.MethodGet, route.Path); named != nil {
+				name = string(named.Name)
+			}
+			name += "." + strings.ToLower(route.Method)
+		}
+		query := make(url.Values)
+		for _, key := range route.Query {
+			query.Set(key, sampleQuery[key])
+		}
+		target := route.Path
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+		add := func(suffix string, variant Variant, status int) {
+			requests = append(requests, RouteRequest{name + suffix,
+				route.Method, target, variant, route, status})
+		}
+		switch route.Response {
+		case PageResponse:
+			add("", PageVariant, fiber.StatusOK)
+			add(".boosted", BoostedVariant, fiber.StatusOK)
+		case FragmentResponse:
+			add("", FragmentVariant, fiber.StatusOK)
+		case RedirectResponse:
+			add("", PageVariant, fiber.StatusSeeOther)
+			add(".fragment", FragmentVariant, fiber.StatusNoContent)
+		case JSONResponse:
+			add("", PageVariant, fiber.StatusOK)
+		}
+	}
+	return requests
+}
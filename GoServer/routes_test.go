@@ -0,0 +1,37 @@
+package server
+
+import "testing"
+
+func TestRouteURL(t *testing.T) {
+	for _, test := range []struct {
+		name   RouteName
+		params []any
+		want   string
+	}{
+		{RouteHome, nil, "/"},
+		{RouteIncrement, []any{"count", 3}, "/increment?count=3"},
+		{RouteLogin, []any{"next", "/counter?x=1"},
+			"/login?next=%2Fcounter%3Fx%3D1"},
+	} {
+		got, err := RouteURL(test.name, test.params...)
+		if err != nil || got != test.want {
+			t.Errorf("RouteURL(%s, %v) = %q, %v; want %q", test.name,
+				test.params, got, err, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		name   RouteName
+		params []any
+	}{
+		{"nowhere", nil},
+		{RouteLogin, []any{"next"}},
+		{RouteLogin, []any{"count", 3}},
+		{RouteIncrement, []any{3, 3}},
+	} {
+		if got, err := RouteURL(test.name, test.params...); err == nil {
+			t.Errorf("RouteURL(%s, %v) = %q; want an error", test.name,
+				test.params, got)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package server is what GoApp and GoTemplApp share: the routes and their
+// handlers, sessions, users, caching, security headers and the command
+// line.  The apps differ only in how they render pages, which each
+// supplies as Views.
+package server
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// How an app renders its pages and fragments.  Pages vary by HX-Boosted:
+// boosted requests get the main layout without the html around it.
+type Views interface {
+	// Loads the templates, failing on any that would fail to render, so
+	// startup and check catch them.  serve calls it again on SIGHUP.
+	Load() error
+	Index(c *fiber.Ctx) error
+	About(c *fiber.Ctx) error
+	Counter(c *fiber.Ctx, count int) error
+	// The counter's form alone, which increment swaps in.
+	CounterForm(c *fiber.Ctx, count int) error
+	FetchData(c *fiber.Ctx) error
+	// The forecasts table alone, which fetchdata polls for.
+	Forecasts(c *fiber.Ctx, forecasts []Forecast) error
+	Login(c *fiber.Ctx, form AccountForm) error
+	Register(c *fiber.Ctx, form AccountForm) error
+	Admin(c *fiber.Ctx, pages []Route) error
+	Users(c *fiber.Ctx, users []*User) error
+	// The error page for c.Path(), or just its main article when
+	// fragment.
+	Error(c *fiber.Ctx, info ErrorInfo, fragment bool) error
+	// The fragment rate limited htmx requests get, which makes the
+	// request again after retryAfter seconds.
+	TooManyRequests(c *fiber.Ctx, method, url string, retryAfter int) error
+}
+
+// An app: its views, and any middleware of its own, which runs after the
+// request gets its ID and before the rest.
+type Frontend struct {
+	Views      Views
+	Middleware []fiber.Handler
+}
+
+// Runs the command named by the program's arguments, exiting on errors.
+func (f *Frontend) Main() {
+	if err := f.Run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Sets up the app with its middleware and routes, getting forecasts from
+// provider.  Also returns a func that reloads what can change while the
+// app runs, which serve calls on SIGHUP.
+func (f *Frontend) NewApp(cfg *Config, provider ForecastProvider) (*fiber.App,
+	func(), error) {
+	sessions, err := newSessionStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, err := NewUserStore(cfg.UsersFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Views that can't render would only fail on the first request for
+	// them, so load them now to fail at startup.
+	views := f.Views
+	if err := views.Load(); err != nil {
+		return nil, nil, err
+	}
+	weather := NewForecastCache(cfg.ForecastTTL, provider)
+	pages := NewPageCache(cfg.PageCacheTTL, cfg.PageCacheSize)
+	reload := func() {
+		if err := views.Load(); err != nil {
+			log.Printf("reloading the views: %v", err)
+			return
+		}
+		pages.Purge()
+		log.Print("reloaded the views and emptied the page cache")
+	}
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: errorHandler(cfg, views),
+	})
+
+	app.Use(recoverPanics(cfg))
+	app.Use(requestid.New())
+	for _, handler := range f.Middleware {
+		app.Use(handler)
+	}
+	app.Use(traceRequests)
+	app.Use(securityHeaders(cfg))
+	app.Use(serveStatic("./wwwroot", assets))
+	app.Use(conditionalGet)
+	app.Use(encryptCookies(cfg))
+	app.Use(loadSessions(sessions))
+	app.Use(authorize(users))
+
+	s := &site{cfg: cfg, views: views, users: users, pages: pages,
+		weather: weather}
+	s.addRoutes(app)
+
+	return app, reload, nil
+}
+
+func (s *site) index(c *fiber.Ctx) error {
+	return s.views.Index(c)
+}
+
+func (s *site) about(c *fiber.Ctx) error {
+	return s.views.About(c)
+}
+
+func (s *site) counter(c *fiber.Ctx) error {
+	return s.views.Counter(c, CurrentSession(c).Count())
+}
+
+func (s *site) increment(c *fiber.Ctx) error {
+	count := c.QueryInt("count", 0)
+	CurrentSession(c).SetCount(count)
+	return s.views.CounterForm(c, count)
+}
+
+func (s *site) fetchData(c *fiber.Ctx) error {
+	return s.views.FetchData(c)
+}
+
+func (s *site) forecasts(c *fiber.Ctx) error {
+	return s.views.Forecasts(c, s.weather.Get(c.UserContext(), time.Now()))
+}
+
+func (s *site) usersPage(c *fiber.Ctx) error {
+	return s.views.Users(c, s.users.All())
+}
+
+func (s *site) adminPage(c *fiber.Ctx) error {
+	return s.views.Admin(c, subpages(CurrentAccess(c), c.Route().Path))
+}
@@ -0,0 +1,7 @@
+package server_test
+
+import "example/server"
+
+// The app the tests in this package run, with views that render only what
+// they check.
+var stub = &server.Frontend{Views: server.StubViews{}}
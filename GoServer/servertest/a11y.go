@@ -0,0 +1,416 @@
+package servertest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"example/server"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// An accessibility problem with an element on a page.
+type A11yProblem struct {
+	Element string // like input#toggle-menu.visually-hidden
+	Rule    string
+	Detail  string
+}
+
+func (p A11yProblem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Rule, p.Element, p.Detail)
+}
+
+// Audits a parsed page, or a fragment of one when page is false, for
+// problems that show without running it in a browser: missing alt text,
+// unlabeled controls and links, headings that skip levels, a missing
+// lang, duplicate IDs and misused ARIA attributes.
+func AuditAccessibility(root *html.Node, page bool) []A11yProblem {
+	a := &audit{ids: make(map[string]int), labelled: make(map[string]bool)}
+	walk(root, func(n *html.Node) {
+		if id := attr(n, "id"); id != "" {
+			a.ids[id]++
+		}
+		if n.DataAtom == atom.Label && accessibleText(n) != "" {
+			a.labelled[attr(n, "for")] = true
+		}
+	})
+	for _, id := range sortedIDs(a.ids) {
+		if count := a.ids[id]; count > 1 {
+			a.report("#"+id, "duplicate-id",
+				fmt.Sprintf("%d elements have this ID", count))
+		}
+	}
+
+	if page {
+		htmlElement := querySelector(root, "html")
+		if lang := strings.TrimSpace(attr(htmlElement, "lang")); lang == "" {
+			a.report("html", "missing-lang", "the page has no lang")
+		}
+		if title := querySelector(root, "title"); title == nil ||
+			textContent(title) == "" {
+			a.report("head", "missing-title", "the page has no title")
+		}
+	}
+
+	lastLevel := 0
+	walk(root, func(n *html.Node) {
+		if level := headingLevel(n); level > 0 {
+			if accessibleText(n) == "" {
+				a.report(describeElement(n), "empty-heading",
+					"the heading has no text")
+			}
+			if lastLevel > 0 && level > lastLevel+1 {
+				a.report(describeElement(n), "heading-order",
+					fmt.Sprintf("h%d follows h%d", level, lastLevel))
+			}
+			lastLevel = level
+		}
+		a.checkImage(n)
+		a.checkControl(n)
+		a.checkName(n)
+		a.checkARIA(n)
+	})
+	if page && querySelector(root, "h1") == nil {
+		a.report("body", "missing-h1", "the page has no h1")
+	}
+	return a.problems
+}
+
+type audit struct {
+	ids map[string]int
+	// The IDs named by labels that have text.
+	labelled map[string]bool
+	problems []A11yProblem
+}
+
+func sortedIDs(ids map[string]int) []string {
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func (a *audit) report(element, rule, detail string) {
+	a.problems = append(a.problems, A11yProblem{element, rule, detail})
+}
+
+func (a *audit) checkImage(n *html.Node) {
+	isImage := n.DataAtom == atom.Img || n.DataAtom == atom.Area ||
+		n.DataAtom == atom.Input && strings.EqualFold(attr(n, "type"), "image")
+	if !isImage || ariaHidden(n) || attr(n, "role") == "presentation" {
+		return
+	}
+	// alt="" is fine: it marks an image as decoration.
+	if _, ok := lookupAttr(n, "alt"); !ok && a.ariaName(n) == "" {
+		a.report(describeElement(n), "missing-alt", "the image has no alt")
+	}
+}
+
+// Form controls need labels.
+func (a *audit) checkControl(n *html.Node) {
+	switch n.DataAtom {
+	case atom.Input:
+		switch strings.ToLower(attr(n, "type")) {
+		case "hidden", "submit", "reset", "button", "image":
+			return
+		}
+	case atom.Select, atom.Textarea:
+	default:
+		return
+	}
+	if a.ariaName(n) != "" || attr(n, "title") != "" ||
+		a.labelled[attr(n, "id")] && attr(n, "id") != "" {
+		return
+	}
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		if parent.DataAtom == atom.Label && accessibleText(parent) != "" {
+			return
+		}
+	}
+	a.report(describeElement(n), "unlabeled-control",
+		"no label, aria-label or aria-labelledby names the control")
+}
+
+// Links and buttons need names a screen reader can read out.
+func (a *audit) checkName(n *html.Node) {
+	_, isLink := lookupAttr(n, "href")
+	isLink = isLink && n.DataAtom == atom.A
+	if !isLink && n.DataAtom != atom.Button || ariaHidden(n) {
+		return
+	}
+	if accessibleText(n) == "" && a.ariaName(n) == "" &&
+		attr(n, "title") == "" {
+		a.report(describeElement(n), "unnamed-"+n.Data,
+			"the "+n.Data+" has no text or aria-label")
+	}
+}
+
+// The name aria-label or aria-labelledby gives n.
+func (a *audit) ariaName(n *html.Node) string {
+	if label := strings.TrimSpace(attr(n, "aria-label")); label != "" {
+		return label
+	}
+	return strings.TrimSpace(attr(n, "aria-labelledby"))
+}
+
+func (a *audit) checkARIA(n *html.Node) {
+	if role, ok := lookupAttr(n, "role"); ok {
+		for _, r := range strings.Fields(role) {
+			if !ariaRoles[r] {
+				a.report(describeElement(n), "aria-role",
+					fmt.Sprintf("%q is not an ARIA role", r))
+			}
+		}
+	}
+	for _, at := range n.Attr {
+		if !strings.HasPrefix(at.Key, "aria-") {
+			continue
+		}
+		if !ariaAttributes[at.Key] {
+			a.report(describeElement(n), "aria-attribute",
+				fmt.Sprintf("%s is not an ARIA attribute", at.Key))
+			continue
+		}
+		if ariaIDRefs[at.Key] {
+			for _, id := range strings.Fields(at.Val) {
+				if a.ids[id] == 0 {
+					a.report(describeElement(n), "aria-reference",
+						fmt.Sprintf("%s names #%s, which isn't on the page",
+							at.Key, id))
+				}
+			}
+		}
+		if ariaBooleans[at.Key] && at.Val != "true" && at.Val != "false" {
+			a.report(describeElement(n), "aria-value",
+				fmt.Sprintf("%s=%q should be true or false", at.Key, at.Val))
+		}
+	}
+	if ariaHidden(n) {
+		if focusable(n) {
+			a.report(describeElement(n), "aria-hidden-focus",
+				"a focusable element is hidden from screen readers")
+		}
+		walk(n, func(child *html.Node) {
+			if focusable(child) {
+				a.report(describeElement(child), "aria-hidden-focus",
+					"a focusable element is inside aria-hidden")
+			}
+		})
+	}
+}
+
+func ariaHidden(n *html.Node) bool {
+	return attr(n, "aria-hidden") == "true"
+}
+
+// Whether n can get keyboard focus.
+func focusable(n *html.Node) bool {
+	if _, disabled := lookupAttr(n, "disabled"); disabled {
+		return false
+	}
+	if tabindex, ok := lookupAttr(n, "tabindex"); ok {
+		return tabindex != "-1"
+	}
+	switch n.DataAtom {
+	case atom.A:
+		_, ok := lookupAttr(n, "href")
+		return ok
+	case atom.Input:
+		return !strings.EqualFold(attr(n, "type"), "hidden")
+	case atom.Button, atom.Select, atom.Textarea:
+		return true
+	}
+	return false
+}
+
+func headingLevel(n *html.Node) int {
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+// The text a screen reader gets from an element's contents: its text,
+// alt text and titles, leaving out what's aria-hidden.
+func accessibleText(n *html.Node) string {
+	var parts []string
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			parts = append(parts, n.Data)
+			return
+		case n.Type != html.ElementNode:
+		case ariaHidden(n):
+			return
+		case attr(n, "aria-label") != "":
+			parts = append(parts, attr(n, "aria-label"))
+			return
+		case n.DataAtom == atom.Img:
+			parts = append(parts, attr(n, "alt"))
+			return
+		case n.DataAtom == atom.Input:
+			switch strings.ToLower(attr(n, "type")) {
+			case "submit", "reset", "button":
+				parts = append(parts, attr(n, "value"))
+			}
+			return
+		}
+		before := len(parts)
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+		// A title stands in for contents with no text, like an icon's.
+		if strings.TrimSpace(strings.Join(parts[before:], "")) == "" {
+			parts = append(parts, attr(n, "title"))
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// Like input#toggle-menu.visually-hidden.
+func describeElement(n *html.Node) string {
+	s := n.Data
+	if id := attr(n, "id"); id != "" {
+		s += "#" + id
+	}
+	for _, class := range strings.Fields(attr(n, "class")) {
+		s += "." + class
+	}
+	return s
+}
+
+// The roles in WAI-ARIA 1.2.
+var ariaRoles = setOf(
+	"alert", "alertdialog", "application", "article", "banner",
+	"blockquote", "button", "caption", "cell", "checkbox", "code",
+	"columnheader", "combobox", "complementary", "contentinfo",
+	"definition", "deletion", "dialog", "directory", "document",
+	"emphasis", "feed", "figure", "form", "generic", "grid", "gridcell",
+	"group", "heading", "img", "insertion", "link", "list", "listbox",
+	"listitem", "log", "main", "marquee", "math", "menu", "menubar",
+	"menuitem", "menuitemcheckbox", "menuitemradio", "meter",
+	"navigation", "none", "note", "option", "paragraph", "presentation",
+	"progressbar", "radio", "radiogroup", "region", "row", "rowgroup",
+	"rowheader", "scrollbar", "search", "searchbox", "separator",
+	"slider", "spinbutton", "status", "strong", "subscript",
+	"superscript", "switch", "tab", "table", "tablist", "tabpanel",
+	"term", "textbox", "time", "timer", "toolbar", "tooltip", "tree",
+	"treegrid", "treeitem",
+)
+
+// The states and properties in WAI-ARIA 1.2.
+var ariaAttributes = setOf(
+	"aria-activedescendant", "aria-atomic", "aria-autocomplete",
+	"aria-braillelabel", "aria-brailleroledescription", "aria-busy",
+	"aria-checked", "aria-colcount", "aria-colindex", "aria-colindextext",
+	"aria-colspan", "aria-controls", "aria-current", "aria-describedby",
+	"aria-description", "aria-details", "aria-disabled", "aria-dropeffect",
+	"aria-errormessage", "aria-expanded", "aria-flowto", "aria-grabbed",
+	"aria-haspopup", "aria-hidden", "aria-invalid", "aria-keyshortcuts",
+	"aria-label", "aria-labelledby", "aria-level", "aria-live",
+	"aria-modal", "aria-multiline", "aria-multiselectable",
+	"aria-orientation", "aria-owns", "aria-placeholder", "aria-posinset",
+	"aria-pressed", "aria-readonly", "aria-relevant", "aria-required",
+	"aria-roledescription", "aria-rowcount", "aria-rowindex",
+	"aria-rowindextext", "aria-rowspan", "aria-selected", "aria-setsize",
+	"aria-sort", "aria-valuemax", "aria-valuemin", "aria-valuenow",
+	"aria-valuetext",
+)
+
+// The attributes whose values are lists of IDs.
+var ariaIDRefs = setOf(
+	"aria-activedescendant", "aria-controls", "aria-describedby",
+	"aria-details", "aria-errormessage", "aria-flowto", "aria-labelledby",
+	"aria-owns",
+)
+
+// The attributes that only take true or false.
+var ariaBooleans = setOf(
+	"aria-atomic", "aria-busy", "aria-disabled", "aria-hidden",
+	"aria-modal", "aria-multiline", "aria-multiselectable",
+	"aria-readonly", "aria-required",
+)
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// Audits every route f renders and reports each one's problems
+// separately, so go test -run Accessibility -v reads as a report per
+// route.
+func Accessibility(t *testing.T, f *server.Frontend) {
+	app := NewApp(t, f, WithAdmin(t, time.Now()))
+	admin := LogInAsAdmin(t, app)
+
+	for _, test := range routeTests() {
+		t.Run(test.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if test.loggedIn {
+				cookies = admin
+			}
+			resp := requestRoute(t, app, test.method, test.target,
+				test.headers, cookies, test.status)
+			defer resp.Body.Close()
+			if !strings.HasPrefix(resp.Header.Get("Content-Type"),
+				"text/html") {
+				t.Skip("not HTML")
+			}
+
+			// Boosted pages and fragments land inside a page, so only
+			// whole pages need a lang, a title and an h1.
+			page := test.headers == nil
+			var root *html.Node
+			var err error
+			if page {
+				root, err = html.Parse(resp.Body)
+			} else {
+				var nodes []*html.Node
+				context := &html.Node{Type: html.ElementNode, Data: "body",
+					DataAtom: atom.Body}
+				nodes, err = html.ParseFragment(resp.Body, context)
+				root = context
+				for _, n := range nodes {
+					root.AppendChild(n)
+				}
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			problems := AuditAccessibility(root, page)
+			if len(problems) == 0 {
+				t.Logf("%s %s: no problems", test.method, test.target)
+				return
+			}
+			var report strings.Builder
+			for _, p := range problems {
+				fmt.Fprintf(&report, "\n  %s", p)
+			}
+			t.Errorf("%s %s: %d accessibility problems:%s", test.method,
+				test.target, len(problems), report.String())
+		})
+	}
+}
@@ -0,0 +1,661 @@
+package servertest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"example/server"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// A browser, minus the browser: it keeps a page as an in-memory DOM and
+// does what htmx would when an element is clicked or triggered.  It
+// makes the same request, with the same headers and form values, and
+// swaps the response into the page the same way, so tests can check
+// what a visitor would see.
+//
+// It understands hx-get, hx-post, hx-put, hx-patch and hx-delete,
+// inherited hx-target and hx-swap, hx-boost on links and forms,
+// hx-swap-oob, HX-Redirect, HX-Retarget and HX-Reswap, and the error
+// responses wwwroot/js/errors.js swaps in.
+type Browser struct {
+	t   *testing.T
+	app *fiber.App
+	jar http.CookieJar
+	URL *url.URL
+	Doc *html.Node
+	// The status of the last response.
+	Status int
+}
+
+// A browser on app, on no page yet.
+func NewBrowser(t *testing.T, app *fiber.App) *Browser {
+	jar, _ := cookiejar.New(nil)
+	start, _ := url.Parse("http://example.com/")
+	return &Browser{t: t, app: app, jar: jar, URL: start}
+}
+
+// Makes a request for u as the browser, cookies and all.
+func (b *Browser) newRequest(method string, u *url.URL,
+	body io.Reader) *http.Request {
+	// Only the path goes in the request line, as in a real one.
+	req := httptest.NewRequest(method, u.RequestURI(), body)
+	req.Host = u.Host
+	for _, cookie := range b.jar.Cookies(u) {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func (b *Browser) do(req *http.Request) *http.Response {
+	b.t.Helper()
+	resp, err := b.app.Test(req, -1)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	u := &url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path}
+	b.jar.SetCookies(u, resp.Cookies())
+	b.Status = resp.StatusCode
+	return resp
+}
+
+// Loads the page at target, following redirects, as if typed into the
+// address bar.
+func (b *Browser) Visit(target string) {
+	b.t.Helper()
+	for redirects := 0; redirects < 10; redirects++ {
+		u := b.URL.ResolveReference(mustParseURL(b.t, target))
+		resp := b.do(b.newRequest(fiber.MethodGet, u, nil))
+		b.URL = u
+		if location := resp.Header.Get("Location"); location != "" &&
+			resp.StatusCode >= 300 && resp.StatusCode < 400 {
+			resp.Body.Close()
+			target = location
+			continue
+		}
+		doc, err := html.Parse(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			b.t.Fatal(err)
+		}
+		b.Doc = doc
+		return
+	}
+	b.t.Fatalf("too many redirects visiting %s", target)
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// The element selector picks, failing the test if there isn't one.
+func (b *Browser) Find(selector string) *html.Node {
+	b.t.Helper()
+	n := querySelector(b.Doc, selector)
+	if n == nil {
+		b.t.Fatalf("no %s on %s", selector, b.URL.Path)
+	}
+	return n
+}
+
+func (b *Browser) FindAll(selector string) []*html.Node {
+	return querySelectorAll(b.Doc, selector)
+}
+
+// The text of the element selector picks, with whitespace collapsed.
+func (b *Browser) Text(selector string) string {
+	b.t.Helper()
+	return textContent(b.Find(selector))
+}
+
+func (b *Browser) Title() string {
+	if title := querySelector(b.Doc, "title"); title != nil {
+		return textContent(title)
+	}
+	return ""
+}
+
+// Types value into the input or textarea selector picks.
+func (b *Browser) Fill(selector, value string) {
+	b.t.Helper()
+	n := b.Find(selector)
+	if n.DataAtom == atom.Textarea {
+		for n.FirstChild != nil {
+			n.RemoveChild(n.FirstChild)
+		}
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: value})
+		return
+	}
+	setAttr(n, "value", value)
+}
+
+// Clicks the element selector picks.  Submit buttons submit their form,
+// and links and forms inside hx-boost are boosted.
+func (b *Browser) Click(selector string) {
+	b.t.Helper()
+	n := b.Find(selector)
+	if isSubmit(n) {
+		if form := closest(n, "form"); form != nil {
+			n = form
+		}
+	}
+	if verb, _ := htmxVerb(n); verb != "" {
+		b.trigger(n)
+		return
+	}
+	if inherited(n, "hx-boost") == "true" {
+		switch n.DataAtom {
+		case atom.A:
+			b.boost(n, fiber.MethodGet, attr(n, "href"))
+			return
+		case atom.Form:
+			method := strings.ToUpper(attr(n, "method"))
+			if method == "" {
+				method = fiber.MethodGet
+			}
+			b.boost(n, method, attr(n, "action"))
+			return
+		}
+	}
+	switch n.DataAtom {
+	case atom.A:
+		b.Visit(attr(n, "href"))
+	default:
+		b.t.Fatalf("clicking %s does nothing", selector)
+	}
+}
+
+// Makes the request the element selector picks makes on its own, as
+// with hx-trigger="every 2s" or "load".
+func (b *Browser) Trigger(selector string) {
+	b.t.Helper()
+	n := b.Find(selector)
+	if verb, _ := htmxVerb(n); verb == "" {
+		b.t.Fatalf("%s has no hx-get, hx-post or the like", selector)
+	}
+	b.trigger(n)
+}
+
+var htmxVerbs = []string{"get", "post", "put", "patch", "delete"}
+
+func htmxVerb(n *html.Node) (string, string) {
+	for _, verb := range htmxVerbs {
+		if path, ok := lookupAttr(n, "hx-"+verb); ok {
+			return strings.ToUpper(verb), path
+		}
+	}
+	return "", ""
+}
+
+func (b *Browser) trigger(n *html.Node) {
+	b.t.Helper()
+	verb, path := htmxVerb(n)
+	var values url.Values
+	if n.DataAtom == atom.Form {
+		values = formValues(n)
+	} else if form := closest(n, "form"); form != nil &&
+		verb != fiber.MethodGet {
+		// htmx includes the enclosing form's values in non-GET requests.
+		values = formValues(form)
+	}
+	target := b.resolveTarget(n)
+	swap := inherited(n, "hx-swap")
+	if swap == "" {
+		swap = "innerHTML"
+	}
+	b.request(n, verb, path, values, target, swap, false)
+}
+
+// Follows a boosted link or submits a boosted form: the response
+// replaces the hx-target's contents, and the URL changes.
+func (b *Browser) boost(n *html.Node, method, path string) {
+	b.t.Helper()
+	var values url.Values
+	if n.DataAtom == atom.Form {
+		values = formValues(n)
+	}
+	target := b.resolveTarget(n)
+	if inherited(n, "hx-target") == "" {
+		target = querySelector(b.Doc, "body")
+	}
+	swap := inherited(n, "hx-swap")
+	if swap == "" {
+		swap = "innerHTML"
+	}
+	b.request(n, method, path, values, target, swap, true)
+}
+
+// The element hx-target names for n, which defaults to n itself.
+func (b *Browser) resolveTarget(n *html.Node) *html.Node {
+	b.t.Helper()
+	selector := inherited(n, "hx-target")
+	switch {
+	case selector == "" || selector == "this":
+		return n
+	case strings.HasPrefix(selector, "closest "):
+		return closest(n, strings.TrimPrefix(selector, "closest "))
+	}
+	return b.Find(selector)
+}
+
+func (b *Browser) request(source *html.Node, method, path string,
+	values url.Values, target *html.Node, swap string, boosted bool) {
+	b.t.Helper()
+	u := b.URL.ResolveReference(mustParseURL(b.t, path))
+	body := strings.NewReader("")
+	if method == fiber.MethodGet {
+		if len(values) > 0 {
+			query := u.Query()
+			for name, vs := range values {
+				query[name] = vs
+			}
+			u.RawQuery = query.Encode()
+		}
+	} else {
+		body = strings.NewReader(values.Encode())
+	}
+	req := b.newRequest(method, u, body)
+	if method != fiber.MethodGet {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Current-URL", b.URL.String())
+	if boosted {
+		req.Header.Set("HX-Boosted", "true")
+	}
+	if id := attr(target, "id"); id != "" {
+		req.Header.Set("HX-Target", id)
+	}
+	if id := attr(source, "id"); id != "" {
+		req.Header.Set("HX-Trigger", id)
+	}
+	if nonce := b.inlineScriptNonce(); nonce != "" {
+		req.Header.Set(server.NonceHeader, nonce)
+	}
+
+	resp := b.do(req)
+	defer resp.Body.Close()
+	if redirect := resp.Header.Get("HX-Redirect"); redirect != "" {
+		b.Visit(redirect)
+		return
+	}
+	// What wwwroot/js/errors.js lets through.
+	retarget := resp.Header.Get("HX-Retarget")
+	if resp.StatusCode >= 400 && resp.StatusCode != fiber.StatusTooManyRequests &&
+		retarget == "" {
+		return
+	}
+	if resp.StatusCode == fiber.StatusNoContent {
+		return
+	}
+	if retarget != "" {
+		target = b.Find(retarget)
+	}
+	if reswap := resp.Header.Get("HX-Reswap"); reswap != "" {
+		swap = reswap
+	}
+
+	context := &html.Node{Type: html.ElementNode, Data: "body",
+		DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(resp.Body, context)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	nodes = b.swapTitle(nodes)
+	nodes = b.swapOutOfBand(nodes)
+	swapNodes(target, strings.Fields(swap)[0], nodes)
+	if boosted && method == fiber.MethodGet {
+		b.URL = u
+	}
+}
+
+// The nonce the page's htmx-config tells htmx to use, which csp.js sends.
+func (b *Browser) inlineScriptNonce() string {
+	meta := querySelector(b.Doc, `meta[name=htmx-config]`)
+	if meta == nil {
+		return ""
+	}
+	var config struct {
+		InlineScriptNonce string `json:"inlineScriptNonce"`
+	}
+	json.Unmarshal([]byte(attr(meta, "content")), &config)
+	return config.InlineScriptNonce
+}
+
+// htmx makes a <title> in a response the page's title.
+func (b *Browser) swapTitle(nodes []*html.Node) []*html.Node {
+	var rest []*html.Node
+	for _, n := range nodes {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Title {
+			if title := querySelector(b.Doc, "title"); title != nil {
+				swapNodes(title, "innerHTML", children(n))
+			}
+			continue
+		}
+		rest = append(rest, n)
+	}
+	return rest
+}
+
+// Swaps elements marked hx-swap-oob into the elements with their IDs, or
+// the elements their selectors pick, and returns the rest.
+func (b *Browser) swapOutOfBand(nodes []*html.Node) []*html.Node {
+	var rest []*html.Node
+	for _, n := range nodes {
+		oob, ok := lookupAttr(n, "hx-swap-oob")
+		if !ok {
+			rest = append(rest, n)
+			continue
+		}
+		removeAttr(n, "hx-swap-oob")
+		swap, selector, found := strings.Cut(oob, ":")
+		if !found {
+			selector = "#" + attr(n, "id")
+		}
+		if swap == "true" {
+			swap = "outerHTML"
+		}
+		target := querySelector(b.Doc, selector)
+		if target == nil {
+			// htmx ignores oob swaps with no target too.
+			continue
+		}
+		if swap == "outerHTML" {
+			swapNodes(target, swap, []*html.Node{n})
+		} else {
+			swapNodes(target, swap, children(n))
+		}
+	}
+	return rest
+}
+
+func children(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		nodes = append(nodes, child)
+	}
+	for _, child := range nodes {
+		n.RemoveChild(child)
+	}
+	return nodes
+}
+
+func swapNodes(target *html.Node, swap string, nodes []*html.Node) {
+	detach := func(nodes []*html.Node) {
+		for _, n := range nodes {
+			if n.Parent != nil {
+				n.Parent.RemoveChild(n)
+			}
+		}
+	}
+	detach(nodes)
+	switch swap {
+	case "innerHTML":
+		for target.FirstChild != nil {
+			target.RemoveChild(target.FirstChild)
+		}
+		for _, n := range nodes {
+			target.AppendChild(n)
+		}
+	case "outerHTML":
+		for _, n := range nodes {
+			target.Parent.InsertBefore(n, target)
+		}
+		target.Parent.RemoveChild(target)
+	case "beforebegin":
+		for _, n := range nodes {
+			target.Parent.InsertBefore(n, target)
+		}
+	case "afterbegin":
+		first := target.FirstChild
+		for _, n := range nodes {
+			target.InsertBefore(n, first)
+		}
+	case "beforeend":
+		for _, n := range nodes {
+			target.AppendChild(n)
+		}
+	case "afterend":
+		next := target.NextSibling
+		for _, n := range nodes {
+			target.Parent.InsertBefore(n, next)
+		}
+	case "delete":
+		target.Parent.RemoveChild(target)
+	}
+}
+
+// The values a form submits: its named inputs, checked boxes, selected
+// options and textareas.
+func formValues(form *html.Node) url.Values {
+	values := url.Values{}
+	walk(form, func(n *html.Node) {
+		name := attr(n, "name")
+		if name == "" {
+			return
+		}
+		if _, disabled := lookupAttr(n, "disabled"); disabled {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Input:
+			switch strings.ToLower(attr(n, "type")) {
+			case "submit", "button", "reset", "image", "file":
+				return
+			case "checkbox", "radio":
+				if _, checked := lookupAttr(n, "checked"); !checked {
+					return
+				}
+				value, ok := lookupAttr(n, "value")
+				if !ok {
+					value = "on"
+				}
+				values.Add(name, value)
+				return
+			}
+			values.Add(name, attr(n, "value"))
+		case atom.Textarea:
+			values.Add(name, textContent(n))
+		case atom.Select:
+			walk(n, func(option *html.Node) {
+				if _, selected := lookupAttr(option, "selected"); selected &&
+					option.DataAtom == atom.Option {
+					values.Add(name, attr(option, "value"))
+				}
+			})
+		}
+	})
+	return values
+}
+
+func isSubmit(n *html.Node) bool {
+	kind := strings.ToLower(attr(n, "type"))
+	return n.DataAtom == atom.Input && kind == "submit" ||
+		n.DataAtom == atom.Button && (kind == "" || kind == "submit")
+}
+
+func lookupAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	value, _ := lookupAttr(n, key)
+	return value
+}
+
+func setAttr(n *html.Node, key, value string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
+
+func removeAttr(n *html.Node, key string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
+
+// The value of key on n or the nearest ancestor that has it, the way
+// htmx inherits hx-target, hx-swap and hx-boost.
+func inherited(n *html.Node, key string) string {
+	for ; n != nil; n = n.Parent {
+		if value, ok := lookupAttr(n, key); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func closest(n *html.Node, selector string) *html.Node {
+	s := parseSelector(selector)
+	for ; n != nil; n = n.Parent {
+		if s.matches(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+func walk(n *html.Node, visit func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			visit(child)
+		}
+		walk(child, visit)
+	}
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// A compound selector, like input#name.wide[type=text].  Descendant
+// combinators, like "form input", are handled by querySelectorAll.
+type selector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   [][2]string
+}
+
+func parseSelector(s string) selector {
+	var sel selector
+	for s != "" {
+		end := strings.IndexAny(s[1:], "#.[") + 1
+		if end == 0 {
+			end = len(s)
+		}
+		part := s[:end]
+		if part[0] == '[' {
+			end = strings.IndexByte(s, ']') + 1
+			part = s[:end]
+			key, value, _ := strings.Cut(part[1:len(part)-1], "=")
+			sel.attrs = append(sel.attrs,
+				[2]string{key, strings.Trim(value, `"'`)})
+			s = s[end:]
+			continue
+		}
+		switch part[0] {
+		case '#':
+			sel.id = part[1:]
+		case '.':
+			sel.classes = append(sel.classes, part[1:])
+		default:
+			sel.tag = part
+		}
+		s = s[end:]
+	}
+	return sel
+}
+
+func (s selector) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode ||
+		s.tag != "" && n.Data != s.tag ||
+		s.id != "" && attr(n, "id") != s.id {
+		return false
+	}
+	classes := strings.Fields(attr(n, "class"))
+	for _, class := range s.classes {
+		found := false
+		for _, c := range classes {
+			found = found || c == class
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, a := range s.attrs {
+		value, ok := lookupAttr(n, a[0])
+		if !ok || a[1] != "" && value != a[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// The elements under root that match selector, a list of compound
+// selectors separated by spaces.
+func querySelectorAll(root *html.Node, selector string) []*html.Node {
+	parts := strings.Fields(selector)
+	matches := []*html.Node{root}
+	for _, part := range parts {
+		s := parseSelector(part)
+		var next []*html.Node
+		seen := make(map[*html.Node]bool)
+		for _, m := range matches {
+			walk(m, func(n *html.Node) {
+				if s.matches(n) && !seen[n] {
+					seen[n] = true
+					next = append(next, n)
+				}
+			})
+		}
+		matches = next
+	}
+	return matches
+}
+
+func querySelector(root *html.Node, selector string) *html.Node {
+	if matches := querySelectorAll(root, selector); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
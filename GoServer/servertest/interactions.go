@@ -0,0 +1,63 @@
+package servertest
+
+import (
+	"testing"
+
+	"example/server"
+)
+
+// Registers from the login page /counter redirects to, then counts.
+func RegisterAndCount(t *testing.T, f *server.Frontend) {
+	b := NewBrowser(t, NewApp(t, f))
+	b.Visit("/counter")
+	if b.URL.Path != "/login" {
+		t.Fatalf("/counter when logged out: got %s", b.URL)
+	}
+	b.Click("form a.ms-3")
+	b.Fill("#username", "admin")
+	b.Fill("#password", "password1")
+	b.Fill("#confirm", "password1")
+	b.Click("form input[type=submit]")
+	if b.URL.Path != "/counter" {
+		t.Fatalf("after registering: got %s, want /counter", b.URL)
+	}
+
+	for _, want := range []string{"Current count: 1", "Current count: 2"} {
+		b.Click("#ClickMeButton")
+		if got := b.Text("#increment-form p"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if forms := b.FindAll("#increment-form"); len(forms) != 1 {
+		t.Errorf("got %d counter forms, want 1", len(forms))
+	}
+}
+
+// Follows a boosted nav link to a page that polls for forecasts.
+func BoostedNavigation(t *testing.T, f *server.Frontend) {
+	b := NewBrowser(t, NewApp(t, f))
+	b.Visit("/")
+	b.Click(`nav a[href="/fetchdata"]`)
+	if b.URL.Path != "/fetchdata" {
+		t.Errorf("got %s, want /fetchdata", b.URL)
+	}
+	if got := b.Title(); got != "Weather forecast" {
+		t.Errorf("got title %q, want Weather forecast", got)
+	}
+	if got := b.Text("nav a.active"); got != "Fetch data" {
+		t.Errorf("got active link %q, want Fetch data", got)
+	}
+	if pages := b.FindAll(".page"); len(pages) != 1 {
+		t.Errorf("got %d .page elements, want 1", len(pages))
+	}
+
+	// The page polls for forecasts, which replace the placeholder.
+	b.Trigger("p[hx-post]")
+	if rows := b.FindAll("table.table tbody tr"); len(rows) != 5 {
+		t.Errorf("got %d forecasts, want 5", len(rows))
+	}
+	b.Trigger("table[hx-post]")
+	if tables := b.FindAll("table.table"); len(tables) != 1 {
+		t.Errorf("got %d forecast tables, want 1", len(tables))
+	}
+}
@@ -0,0 +1,266 @@
+// Package servertest tests apps built on server: it has the helpers their
+// tests share, a browser that does what htmx would, an accessibility
+// audit, and the tests every app runs against its own views, with golden
+// files in the app's testdata.
+package servertest
+
+import (
+	"context"
+	"flag"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"example/server"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Run go test -update to rewrite the golden files after changing a page on
+// purpose, then review the diff.
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+var goldenDate = time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+// Forecasts that come out the same every time, whatever the date.
+func fixedForecasts(ctx context.Context, _ time.Time) []server.Forecast {
+	return server.MakeForecasts(ctx, goldenDate,
+		rand.New(rand.NewSource(1)).Intn)
+}
+
+// An app with f's views for tests, configured by flags after the tests'
+// defaults.
+func NewApp(t *testing.T, f *server.Frontend, flags ...string) *fiber.App {
+	t.Helper()
+	var cfg server.Config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	// Set explicitly, because the environment supplies the defaults.
+	err := fs.Parse(append([]string{
+		"-production=false",
+		"-rate-limit=false",
+		"-sessions=memory",
+		"-users=",
+		"-page-cache-ttl=0",
+		"-forecast-ttl=0",
+	}, flags...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, _, err := f.NewApp(&cfg, fixedForecasts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+// Headers for each way a page gets requested.
+var (
+	Boosted  = map[string]string{"HX-Request": "true", "HX-Boosted": "true"}
+	Fragment = map[string]string{"HX-Request": "true"}
+)
+
+// Sends a request to app, with body as a form when there is one.
+func Request(t *testing.T, app *fiber.App, method, target string,
+	body io.Reader, headers map[string]string,
+	cookies []*http.Cookie) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, target, body)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// A -users flag for NewApp, naming a users file with an admin in it,
+// created at created, since registering never makes anybody an admin.
+func WithAdmin(t *testing.T, created time.Time) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "users.json")
+	users, err := server.NewUserStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := users.Register("admin", "password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Saved by Grant.
+	admin.Created = created
+	if err := users.Grant("admin", server.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	return "-users=" + path
+}
+
+// Logs in the admin of an app made with WithAdmin, and returns the cookies
+// that keep them logged in.
+func LogInAsAdmin(t *testing.T, app *fiber.App) []*http.Cookie {
+	t.Helper()
+	form := url.Values{"username": {"admin"}, "password": {"password1"}}
+	resp := Request(t, app, fiber.MethodPost, "/login",
+		strings.NewReader(form.Encode()), nil, nil)
+	if resp.StatusCode != fiber.StatusSeeOther {
+		t.Fatalf("logging in: got %s", resp.Status)
+	}
+	return resp.Cookies()
+}
+
+var (
+	policyNonce = regexp.MustCompile(`'nonce-([^']+)'`)
+	hashedAsset = regexp.MustCompile(`\.[0-9a-f]{10}\.(css|js)\b`)
+	betweenTags = regexp.MustCompile(`>\s*<`)
+)
+
+// Replaces what changes from one request to the next, including now's
+// date, and puts each tag on its own line, so the golden files only change
+// when a page does and the diffs are readable.  Redirects have no body, so
+// they get where they lead instead.
+func normalize(resp *http.Response, body string, now time.Time) string {
+	for _, header := range []string{"Location", "HX-Redirect"} {
+		if value := resp.Header.Get(header); value != "" {
+			body = header + ": " + value + "\n" + body
+		}
+	}
+	if match := policyNonce.FindStringSubmatch(
+		resp.Header.Get("Content-Security-Policy")); match != nil {
+		for _, form := range server.NonceForms(match[1]) {
+			body = strings.ReplaceAll(body, string(form), "NONCE")
+		}
+	}
+	if id := resp.Header.Get("X-Request-Id"); id != "" {
+		body = strings.ReplaceAll(body, id, "REQUEST-ID")
+	}
+	body = strings.ReplaceAll(body, now.Format("1/2/2006"), "TODAY")
+	body = hashedAsset.ReplaceAllString(body, ".HASH.$1")
+	body = betweenTags.ReplaceAllString(body, ">\n<")
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Checks resp against a golden file, where the date of now, when the page's
+// data was made, is TODAY.
+func checkGolden(t *testing.T, name string, resp *http.Response,
+	now time.Time) {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := normalize(resp, string(body), now)
+	ext := ".html"
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		ext = ".json"
+	}
+	path := filepath.Join("testdata", "golden", name+ext)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s; run go test -update if that's on "+
+			"purpose.\n--- got:\n%s", name, path, got)
+	}
+}
+
+type routeTest struct {
+	name     string
+	method   string
+	target   string
+	headers  map[string]string
+	loggedIn bool
+	status   int
+}
+
+// Every page and fragment, requested each way it gets requested, and the
+// not found page.  /debug/vars is left out: its memstats change with
+// every request, and TestDebugVars checks it.
+func routeTests() []routeTest {
+	headers := map[server.Variant]map[string]string{
+		server.BoostedVariant:  Boosted,
+		server.FragmentVariant: Fragment,
+	}
+	var tests []routeTest
+	for _, req := range server.RouteRequests() {
+		if req.Route.Name == server.RouteDebugVars {
+			continue
+		}
+		tests = append(tests, routeTest{req.Name, req.Method, req.Target,
+			headers[req.Variant], len(req.Route.Roles) > 0, req.Status})
+	}
+	return append(tests,
+		routeTest{"notfound", "GET", "/missing", nil, false, 404},
+		routeTest{"notfound.boosted", "GET", "/missing", Boosted, false, 404},
+		routeTest{"notfound.fragment", "GET", "/missing", Fragment, false,
+			404},
+	)
+}
+
+// Requests one of routeTests and checks its status.
+func requestRoute(t *testing.T, app *fiber.App, method, target string,
+	headers map[string]string, cookies []*http.Cookie,
+	status int) *http.Response {
+	t.Helper()
+	var body io.Reader
+	if method == fiber.MethodPost {
+		body = strings.NewReader("")
+	}
+	resp := Request(t, app, method, target, body, headers, cookies)
+	if resp.StatusCode != status {
+		t.Fatalf("got %s, want %d", resp.Status, status)
+	}
+	return resp
+}
+
+// Checks every page and fragment f renders against the golden files.
+func GoldenRoutes(t *testing.T, f *server.Frontend) {
+	// The users page shows when admin was created, so that's now.
+	now := time.Now()
+	app := NewApp(t, f, WithAdmin(t, now))
+	admin := LogInAsAdmin(t, app)
+
+	for _, test := range routeTests() {
+		t.Run(test.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if test.loggedIn {
+				cookies = admin
+			}
+			resp := requestRoute(t, app, test.method, test.target,
+				test.headers, cookies, test.status)
+			checkGolden(t, test.name, resp, now)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/encryptcookie"
+	"github.com/gofiber/fiber/v2/middleware/session"
+)
+
+const sessionCookie = "session_id"
+
+// The visitor's session.  Handlers and templates read and write it through
+// typed accessors, and the sessions middleware saves it after the handler
+// returns, if anything changed.
+type Session struct {
+	session   *session.Session
+	changed   bool
+	destroyed bool
+}
+
+// Keys of values stored in sessions.
+const (
+	countKey = "count"
+	userKey  = "user"
+)
+
+// Reads a value from the session.  Requests that never reached the
+// sessions middleware, like some errors, have no session and read zeroes.
+func sessionValue[T any](s *Session, key string) (T, bool) {
+	if s == nil {
+		var zero T
+		return zero, false
+	}
+	value, ok := s.session.Get(key).(T)
+	return value, ok
+}
+
+func setSessionValue[T any](s *Session, key string, value T) {
+	s.session.Set(key, value)
+	s.changed = true
+}
+
+func (s *Session) ID() string {
+	return s.session.ID()
+}
+
+func (s *Session) IsNew() bool {
+	return s.session.Fresh()
+}
+
+// The Counter page's count.
+func (s *Session) Count() int {
+	count, _ := sessionValue[int](s, countKey)
+	return count
+}
+
+func (s *Session) SetCount(count int) {
+	setSessionValue(s, countKey, count)
+}
+
+// The user name the visitor is logged in as, or "".
+func (s *Session) Username() string {
+	name, _ := sessionValue[string](s, userKey)
+	return name
+}
+
+// Logs the visitor in as name, or out when name is "", with a new session
+// ID either way.
+func (s *Session) SetUsername(name string) error {
+	if err := s.Rotate(); err != nil {
+		return err
+	}
+	if name == "" {
+		s.session.Delete(userKey)
+	} else {
+		setSessionValue(s, userKey, name)
+	}
+	return nil
+}
+
+// Gives the session a new ID, keeping its data.  Call it whenever the
+// visitor's privileges change, like logging in or out, so a session ID
+// captured beforehand is useless afterwards.
+func (s *Session) Rotate() error {
+	s.changed = true
+	return s.session.Regenerate()
+}
+
+// Deletes the session and its cookie.
+func (s *Session) Destroy() error {
+	s.destroyed = true
+	return s.session.Destroy()
+}
+
+// The session of the current request, loaded by the sessions middleware.
+func CurrentSession(c *fiber.Ctx) *Session {
+	s, _ := c.Locals("session").(*Session)
+	return s
+}
+
+// Creates the session store described by cfg.SessionStore, which is
+// "memory", "file:<directory>" or "bolt:<database file>".
+func newSessionStore(cfg *Config) (*session.Store, error) {
+	var storage fiber.Storage
+	kind, path, _ := strings.Cut(cfg.SessionStore, ":")
+	switch kind {
+	case "memory":
+		// The session package's default.
+	case "file":
+		var err error
+		if storage, err = NewFileStorage(path); err != nil {
+			return nil, err
+		}
+	case "bolt":
+		var err error
+		if storage, err = NewBoltStorage(path); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown session store %q", cfg.SessionStore)
+	}
+	return session.New(session.Config{
+		Expiration:     cfg.SessionTTL,
+		Storage:        storage,
+		KeyLookup:      "cookie:" + sessionCookie,
+		CookieHTTPOnly: true,
+		CookieSecure:   cfg.Production,
+		CookieSameSite: fiber.CookieSameSiteLaxMode,
+	}), nil
+}
+
+// Middleware that encrypts and authenticates cookies, so visitors can
+// neither read nor forge them.
+func encryptCookies(cfg *Config) fiber.Handler {
+	key := cfg.SessionKey
+	if key == "" {
+		log.Print("No session key configured; " +
+			"sessions will not survive a restart.")
+		key = encryptcookie.GenerateKey()
+	}
+	return encryptcookie.New(encryptcookie.Config{Key: key})
+}
+
+// Middleware that loads the visitor's session into Locals, and saves it
+// once the rest of the chain is done with it.  Sessions that were never
+// written to aren't saved, so visitors only get a cookie once there is
+// something to remember.
+func loadSessions(store *session.Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sess, err := store.Get(c)
+		if err != nil {
+			return err
+		}
+		s := &Session{session: sess}
+		c.Locals("session", s)
+
+		err = c.Next()
+		if s.destroyed || (!s.changed && sess.Fresh()) {
+			return err
+		}
+		if saveErr := sess.Save(); saveErr != nil && err == nil {
+			err = saveErr
+		}
+		return err
+	}
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Session stores that survive restarts.  Both implement fiber.Storage, and
+// store each value behind its expiry time, as Unix nanoseconds.
+
+func encodeEntry(value []byte, exp time.Duration) []byte {
+	var expires int64
+	if exp > 0 {
+		expires = time.Now().Add(exp).UnixNano()
+	}
+	entry := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(entry, uint64(expires))
+	copy(entry[8:], value)
+	return entry
+}
+
+// Returns the value in entry, or nil if it has expired.
+func decodeEntry(entry []byte, now time.Time) []byte {
+	if len(entry) < 8 {
+		return nil
+	}
+	expires := int64(binary.BigEndian.Uint64(entry))
+	if expires != 0 && now.UnixNano() > expires {
+		return nil
+	}
+	return entry[8:]
+}
+
+// How often stores delete expired entries.
+const sweepInterval = 10 * time.Minute
+
+// Stores each value in its own file.
+type FileStorage struct {
+	dir       string
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if dir == "" {
+		return nil, errors.New("file session store needs a directory")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStorage{dir: dir, lastSweep: time.Now()}, nil
+}
+
+// Keys are hex encoded, so they can't escape the directory.
+func (s *FileStorage) path(key string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(key))+".session")
+}
+
+func (s *FileStorage) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+	entry, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	value := decodeEntry(entry, time.Now())
+	if value == nil {
+		return nil, s.Delete(key)
+	}
+	return value, nil
+}
+
+func (s *FileStorage) Set(key string, value []byte, exp time.Duration) error {
+	if key == "" || len(value) == 0 {
+		return nil
+	}
+	s.sweep()
+	tmp, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(encodeEntry(value, exp)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+func (s *FileStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FileStorage) Reset() error {
+	return s.removeIf(func(string) bool { return true })
+}
+
+func (s *FileStorage) Close() error {
+	return nil
+}
+
+func (s *FileStorage) sweep() {
+	s.mutex.Lock()
+	now := time.Now()
+	due := now.Sub(s.lastSweep) > sweepInterval
+	if due {
+		s.lastSweep = now
+	}
+	s.mutex.Unlock()
+	if due {
+		s.removeIf(func(path string) bool {
+			entry, err := os.ReadFile(path)
+			return err == nil && decodeEntry(entry, now) == nil
+		})
+	}
+}
+
+func (s *FileStorage) removeIf(remove func(path string) bool) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(s.dir, entry.Name())
+		if strings.HasSuffix(entry.Name(), ".session") && remove(path) {
+			if err := os.Remove(path); err != nil &&
+				!errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stores values in a bolt database file.
+type BoltStorage struct {
+	db        *bolt.DB
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+var sessionsBucket = []byte("sessions")
+
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	if path == "" {
+		return nil, errors.New("bolt session store needs a database file")
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(sessionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStorage{db: db, lastSweep: time.Now()}, nil
+}
+
+func (s *BoltStorage) Get(key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		entry := tx.Bucket(sessionsBucket).Get([]byte(key))
+		if decoded := decodeEntry(entry, time.Now()); decoded != nil {
+			// Bolt's slices are only valid inside the transaction.
+			value = append([]byte(nil), decoded...)
+		}
+		return nil
+	})
+	return value, err
+}
+
+func (s *BoltStorage) Set(key string, value []byte, exp time.Duration) error {
+	if key == "" || len(value) == 0 {
+		return nil
+	}
+	s.sweep()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(key),
+			encodeEntry(value, exp))
+	})
+}
+
+func (s *BoltStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(key))
+	})
+}
+
+func (s *BoltStorage) Reset() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(sessionsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(sessionsBucket)
+		return err
+	})
+}
+
+func (s *BoltStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStorage) sweep() {
+	s.mutex.Lock()
+	now := time.Now()
+	due := now.Sub(s.lastSweep) > sweepInterval
+	if due {
+		s.lastSweep = now
+	}
+	s.mutex.Unlock()
+	if !due {
+		return
+	}
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sessionsBucket)
+		var expired [][]byte
+		bucket.ForEach(func(key, entry []byte) error {
+			if decodeEntry(entry, now) == nil {
+				expired = append(expired, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
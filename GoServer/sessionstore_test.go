@@ -0,0 +1,170 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Each store, opened on a fresh directory, and reopened on the same one
+// to check what survives a restart.
+var sessionStores = []struct {
+	name string
+	open func(t *testing.T, dir string) fiber.Storage
+}{
+	{"file", func(t *testing.T, dir string) fiber.Storage {
+		s, err := NewFileStorage(filepath.Join(dir, "sessions"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}},
+	{"bolt", func(t *testing.T, dir string) fiber.Storage {
+		s, err := NewBoltStorage(filepath.Join(dir, "sessions.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}},
+}
+
+func getSession(t *testing.T, s fiber.Storage, key string) string {
+	t.Helper()
+	value, err := s.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(value)
+}
+
+func TestSessionStoreRoundTrip(t *testing.T) {
+	for _, store := range sessionStores {
+		t.Run(store.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := store.open(t, dir)
+			if err := s.Set("id", []byte("data"), time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Set("forever", []byte("more"), 0); err != nil {
+				t.Fatal(err)
+			}
+			if got := getSession(t, s, "id"); got != "data" {
+				t.Errorf("got %q, want data", got)
+			}
+			if got := getSession(t, s, "missing"); got != "" {
+				t.Errorf("missing key: got %q", got)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			s = store.open(t, dir)
+			defer s.Close()
+			if got := getSession(t, s, "id"); got != "data" {
+				t.Errorf("after reopening: got %q, want data", got)
+			}
+			if got := getSession(t, s, "forever"); got != "more" {
+				t.Errorf("without expiry: got %q, want more", got)
+			}
+		})
+	}
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	for _, store := range sessionStores {
+		t.Run(store.name, func(t *testing.T) {
+			s := store.open(t, t.TempDir())
+			defer s.Close()
+			if err := s.Set("id", []byte("data"),
+				10*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(20 * time.Millisecond)
+			if got := getSession(t, s, "id"); got != "" {
+				t.Errorf("got %q after it expired", got)
+			}
+		})
+	}
+}
+
+// Expired entries nobody asks for again get swept up by later writes.
+func TestSessionStoreSweep(t *testing.T) {
+	dir := t.TempDir()
+	files, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Set("old", []byte("data"),
+		time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	files.lastSweep = time.Now().Add(-2 * sweepInterval)
+	if err := files.Set("new", []byte("data"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(files.path("old")); !os.IsNotExist(err) {
+		t.Errorf("the expired file is still there: %v", err)
+	}
+
+	bolts, err := NewBoltStorage(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolts.Close()
+	if err := bolts.Set("old", []byte("data"),
+		time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	bolts.lastSweep = time.Now().Add(-2 * sweepInterval)
+	if err := bolts.Set("new", []byte("data"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	entries := 0
+	bolts.db.View(func(tx *bolt.Tx) error {
+		entries = tx.Bucket(sessionsBucket).Stats().KeyN
+		return nil
+	})
+	if entries != 1 {
+		t.Errorf("got %d entries, want just the new one", entries)
+	}
+}
+
+func TestSessionStoreDelete(t *testing.T) {
+	for _, store := range sessionStores {
+		t.Run(store.name, func(t *testing.T) {
+			s := store.open(t, t.TempDir())
+			defer s.Close()
+			for _, key := range []string{"a", "b", "c"} {
+				if err := s.Set(key, []byte(key), time.Hour); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.Delete("a"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Delete("missing"); err != nil {
+				t.Errorf("deleting a missing key: %v", err)
+			}
+			if got := getSession(t, s, "a"); got != "" {
+				t.Errorf("got %q after deleting it", got)
+			}
+			if got := getSession(t, s, "b"); got != "b" {
+				t.Errorf("got %q, want b", got)
+			}
+			if err := s.Reset(); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range []string{"b", "c"} {
+				if got := getSession(t, s, key); got != "" {
+					t.Errorf("got %q for %s after resetting", got, key)
+				}
+			}
+		})
+	}
+}
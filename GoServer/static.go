@@ -0,0 +1,205 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Precompressed variants, in order of preference.
+var encodings = []struct {
+	name   string
+	suffix string
+	writer func(io.Writer) io.WriteCloser
+}{
+	{"br", ".br", func(w io.Writer) io.WriteCloser {
+		return brotli.NewWriterLevel(w, brotli.BestCompression)
+	}},
+	{"gzip", ".gz", func(w io.Writer) io.WriteCloser {
+		gz, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		return gz
+	}},
+}
+
+// Text compresses well; fonts like woff and images already are compressed.
+var compressible = map[string]bool{
+	".css": true, ".js": true, ".map": true, ".svg": true, ".html": true,
+	".json": true, ".txt": true, ".md": true, ".ico": true, ".eot": true,
+	".ttf": true, ".otf": true,
+}
+
+func isVariant(name string) bool {
+	for _, encoding := range encodings {
+		if strings.HasSuffix(name, encoding.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Writes .br and .gz variants next to the compressible files under root,
+// skipping variants that are already newer than their file.  Variants that
+// wouldn't be smaller than the file aren't kept; a marker newer than the
+// file records that instead, so they aren't compressed again every time.
+func compressAssets(root string) error {
+	return filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || isVariant(file) ||
+			!compressible[strings.ToLower(filepath.Ext(file))] {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		for _, encoding := range encodings {
+			variant := file + encoding.suffix
+			if newerThan(variant, info) || newerThan(notSmaller(variant), info) {
+				continue
+			}
+			if err := compressFile(file, variant, info.Size(),
+				encoding.writer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func newerThan(name string, info fs.FileInfo) bool {
+	vinfo, err := os.Stat(name)
+	return err == nil && !vinfo.ModTime().Before(info.ModTime())
+}
+
+// The marker for a variant that isn't smaller than its file.  It's a
+// dotfile, which serveStatic and export leave out.
+func notSmaller(variant string) string {
+	return filepath.Join(filepath.Dir(variant),
+		"."+filepath.Base(variant)+".notsmaller")
+}
+
+func compressFile(file, variant string, size int64,
+	newWriter func(io.Writer) io.WriteCloser) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(filepath.Dir(variant), ".compress-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	w := newWriter(out)
+	_, err = io.Copy(w, in)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(out.Name())
+	if err != nil {
+		return err
+	}
+	if info.Size() >= size {
+		os.Remove(variant)
+		return os.WriteFile(notSmaller(variant), nil, 0o644)
+	}
+	os.Remove(notSmaller(variant))
+	return os.Rename(out.Name(), variant)
+}
+
+// The encodings acceptEncoding allows, ignoring those with q=0.
+func acceptedEncodings(acceptEncoding string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(value, 64)
+		}
+		if q > 0 {
+			accepted[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+	return accepted
+}
+
+// Whether a file modified at modTime is unchanged since the time in an
+// If-Modified-Since header.  Header times only have whole seconds.
+func notModifiedSince(ifModifiedSince string, modTime time.Time) bool {
+	since, err := http.ParseTime(ifModifiedSince)
+	return err == nil && !modTime.Truncate(time.Second).After(since)
+}
+
+// Handler that serves files under root, choosing a precompressed variant
+// when the client accepts one, unless it asked for a variant itself.
+// Dotfiles aren't served.  Fingerprinted URLs from the manifest are
+// served with headers that let them be cached forever.  Requests for
+// anything else go on to the next handler.
+func serveStatic(root string, manifest *AssetManifest) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		name := path.Clean("/" + c.Path())
+		immutable := false
+		if original, ok := manifest.File(name); ok {
+			name, immutable = original, true
+		}
+		if strings.HasPrefix(path.Base(name), ".") {
+			return c.Next()
+		}
+		file := filepath.Join(root, filepath.FromSlash(name))
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			return c.Next()
+		}
+
+		c.Type(filepath.Ext(file))
+		c.Vary(fiber.HeaderAcceptEncoding)
+		c.Set(fiber.HeaderLastModified,
+			info.ModTime().UTC().Format(http.TimeFormat))
+		if immutable {
+			c.Set(fiber.HeaderCacheControl,
+				"public, max-age=31536000, immutable")
+		}
+		if notModifiedSince(c.Get(fiber.HeaderIfModifiedSince), info.ModTime()) {
+			c.Status(fiber.StatusNotModified)
+			return nil
+		}
+		accepted := acceptedEncodings(c.Get(fiber.HeaderAcceptEncoding))
+		for _, encoding := range encodings {
+			if isVariant(name) || !accepted[encoding.name] {
+				continue
+			}
+			vinfo, err := os.Stat(file + encoding.suffix)
+			if err == nil && !vinfo.ModTime().Before(info.ModTime()) {
+				c.Set(fiber.HeaderContentEncoding, encoding.name)
+				file, info = file+encoding.suffix, vinfo
+				break
+			}
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		// fasthttp closes the file once it's sent.
+		c.Response().SetBodyStream(f, int(info.Size()))
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCompressAssets(t *testing.T) {
+	root := t.TempDir()
+	small := filepath.Join(root, "small.css")
+	large := filepath.Join(root, "large.css")
+	long := strings.Repeat("body { margin: 0; }\n", 100)
+	if err := os.WriteFile(small, []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, []byte(long), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(small, old, old)
+
+	if err := compressAssets(root); err != nil {
+		t.Fatal(err)
+	}
+	for _, encoding := range encodings {
+		if _, err := os.Stat(large + encoding.suffix); err != nil {
+			t.Errorf("large.css%s: %v", encoding.suffix, err)
+		}
+		if _, err := os.Stat(small + encoding.suffix); err == nil {
+			t.Errorf("kept small.css%s, which isn't smaller",
+				encoding.suffix)
+		}
+	}
+
+	marker := notSmaller(small + ".gz")
+	before, err := os.Stat(marker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := compressAssets(root); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.Stat(marker)
+	if err != nil || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("compressed small.css again: %v", err)
+	}
+}
+
+func TestServeStaticVariants(t *testing.T) {
+	root := t.TempDir()
+	css := filepath.Join(root, "site.css")
+	if err := os.WriteFile(css, []byte(strings.Repeat("p {}\n", 100)),
+		0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "tiny.css"), []byte("a"),
+		0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressAssets(root); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := os.ReadFile(css + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := LoadAssetManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Use(serveStatic(root, manifest))
+
+	for _, test := range []struct {
+		target, encoding string
+		status           int
+		body             []byte
+	}{
+		{"/site.css", "gzip", 200, gz},
+		// Asked for by name, the variant is just a file.
+		{"/site.css.gz", "", 200, gz},
+		{"/" + filepath.Base(notSmaller(filepath.Join(root, "tiny.css.gz"))),
+			"", 404, nil},
+	} {
+		req := httptest.NewRequest(fiber.MethodGet, test.target, nil)
+		req.Header.Set(fiber.HeaderAcceptEncoding, "gzip")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != test.status ||
+			resp.Header.Get(fiber.HeaderContentEncoding) != test.encoding ||
+			test.body != nil && string(body) != string(test.body) {
+			t.Errorf("%s: got %s, Content-Encoding %q", test.target,
+				resp.Status, resp.Header.Get(fiber.HeaderContentEncoding))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// What the tests in package server_test, which use servertest, need from
+// inside the package.
+var (
+	ExportSite      = exportSite
+	IncrementPolicy = incrementPolicy
+)
+
+// Views for the server's own tests, which check what the server does
+// rather than how pages look.  Pages are a title and the links and hx-
+// attributes that tests and exports follow, inside an html element unless
+// they're boosted.
+type StubViews struct{}
+
+func (StubViews) page(c *fiber.Ctx, title string, main string) error {
+	c.Vary("HX-Boosted")
+	c.Type("html")
+	main = "<h1>" + html.EscapeString(title) + "</h1>" + main
+	if c.Get("HX-Boosted") == "true" {
+		return c.SendString(main)
+	}
+	return c.SendString("<!DOCTYPE html><html><body>" + main +
+		"</body></html>")
+}
+
+func (StubViews) fragment(c *fiber.Ctx, format string, args ...any) error {
+	c.Type("html")
+	return c.SendString(fmt.Sprintf(format, args...))
+}
+
+func (StubViews) Load() error {
+	return nil
+}
+
+func (v StubViews) Index(c *fiber.Ctx) error {
+	return v.page(c, "Home", "")
+}
+
+func (v StubViews) About(c *fiber.Ctx) error {
+	return v.page(c, "About", "")
+}
+
+func (v StubViews) Counter(c *fiber.Ctx, count int) error {
+	return v.page(c, "Counter", fmt.Sprintf(`<a hx-get="%s">%d</a>`,
+		URLFor(RouteIncrement, "count", count+1), count))
+}
+
+func (v StubViews) CounterForm(c *fiber.Ctx, count int) error {
+	return v.fragment(c, `<a hx-get="%s">%d</a>`,
+		URLFor(RouteIncrement, "count", count+1), count)
+}
+
+func (v StubViews) FetchData(c *fiber.Ctx) error {
+	return v.page(c, "Weather forecast",
+		`<p hx-post="`+URLFor(RouteForecasts)+`">Loading...</p>`)
+}
+
+func (v StubViews) Forecasts(c *fiber.Ctx, forecasts []Forecast) error {
+	return v.fragment(c, "<p>%d forecasts</p>", len(forecasts))
+}
+
+func (v StubViews) Login(c *fiber.Ctx, form AccountForm) error {
+	return v.page(c, "Log in", html.EscapeString(form.Error))
+}
+
+func (v StubViews) Register(c *fiber.Ctx, form AccountForm) error {
+	return v.page(c, "Register", html.EscapeString(form.Error))
+}
+
+func (v StubViews) Admin(c *fiber.Ctx, pages []Route) error {
+	var links strings.Builder
+	for _, page := range pages {
+		fmt.Fprintf(&links, `<a href="%s">%s</a>`, page.Path, page.Title)
+	}
+	return v.page(c, "Admin", links.String())
+}
+
+func (v StubViews) Users(c *fiber.Ctx, users []*User) error {
+	return v.page(c, "Users", fmt.Sprintf("<p>%d users</p>", len(users)))
+}
+
+func (v StubViews) Error(c *fiber.Ctx, info ErrorInfo, fragment bool) error {
+	if fragment {
+		return v.fragment(c, "<p>%d</p>", info.Status)
+	}
+	return v.page(c, info.Title, "")
+}
+
+func (v StubViews) TooManyRequests(c *fiber.Ctx, method, url string,
+	retryAfter int) error {
+	return v.fragment(c, "<p>Trying %s %s again in %ds</p>", method,
+		html.EscapeString(url), retryAfter)
+}
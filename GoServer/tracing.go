@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// A minimal OpenTelemetry-style tracer.  Spans are exported as JSON lines
+// to a file so no collector is needed to look at them.
+
+type TraceID [16]byte
+type SpanID [8]byte
+
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+type Span struct {
+	tracer     *Tracer
+	name       string
+	context    SpanContext
+	parentID   SpanID
+	start      time.Time
+	attributes map[string]interface{}
+	err        error
+}
+
+type Tracer struct {
+	mutex   sync.Mutex
+	out     io.WriteCloser
+	encoder *json.Encoder
+}
+
+// The tracer used by the app.  It discards spans until configured.
+var DefaultTracer = &Tracer{}
+
+// Exports spans to path, appending to whatever is already there.
+func (t *Tracer) ExportToFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.out = f
+	t.encoder = json.NewEncoder(f)
+	return nil
+}
+
+func (t *Tracer) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.out == nil {
+		return nil
+	}
+	err := t.out.Close()
+	t.out, t.encoder = nil, nil
+	return err
+}
+
+type spanKey struct{}
+
+// Starts a span that is a child of the span in ctx, if there is one.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	span := &Span{tracer: t, name: name, start: time.Now()}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.context.TraceID = parent.context.TraceID
+		span.parentID = parent.context.SpanID
+		span.context.Sampled = parent.context.Sampled
+	} else if remote, ok := ctx.Value(remoteSpanKey{}).(SpanContext); ok {
+		span.context.TraceID = remote.TraceID
+		span.parentID = remote.SpanID
+		span.context.Sampled = remote.Sampled
+	} else {
+		rand.Read(span.context.TraceID[:])
+		span.context.Sampled = true
+	}
+	rand.Read(span.context.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// The span in ctx, or nil if there isn't one.  Span's methods do nothing
+// on nil spans, like OpenTelemetry's no-op span, so callers needn't check.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.name = name
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// Marks the span as failed.  Nil errors are ignored so callers can pass
+// whatever they got back.
+func (s *Span) RecordError(err error) {
+	if s != nil && err != nil {
+		s.err = err
+	}
+}
+
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+type spanRecord struct {
+	TraceID      string                 `json:"traceId"`
+	SpanID       string                 `json:"spanId"`
+	ParentSpanID string                 `json:"parentSpanId,omitempty"`
+	Name         string                 `json:"name"`
+	StartTime    time.Time              `json:"startTime"`
+	EndTime      time.Time              `json:"endTime"`
+	DurationMS   float64                `json:"durationMs"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Status       string                 `json:"status"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	t := s.tracer
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.encoder == nil || !s.context.Sampled {
+		return
+	}
+	record := spanRecord{
+		TraceID:    hex.EncodeToString(s.context.TraceID[:]),
+		SpanID:     hex.EncodeToString(s.context.SpanID[:]),
+		Name:       s.name,
+		StartTime:  s.start,
+		EndTime:    end,
+		DurationMS: float64(end.Sub(s.start).Microseconds()) / 1000,
+		Attributes: s.attributes,
+		Status:     "OK",
+	}
+	if s.parentID != (SpanID{}) {
+		record.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != nil {
+		record.Status = "ERROR"
+		record.Error = s.err.Error()
+	}
+	t.encoder.Encode(record)
+}
+
+// W3C trace context: https://www.w3.org/TR/trace-context/#traceparent-header
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" +
+		hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+func parseTraceparent(header string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || sc.TraceID == (TraceID{}) || sc.SpanID == (SpanID{}) {
+		return sc, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+type remoteSpanKey struct{}
+
+// Middleware that wraps each request in a span, continuing the trace from
+// an incoming traceparent header and returning the span's own traceparent.
+func traceRequests(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	if remote, ok := parseTraceparent(c.Get("traceparent")); ok {
+		ctx = context.WithValue(ctx, remoteSpanKey{}, remote)
+	}
+	ctx, span := DefaultTracer.Start(ctx, c.Method()+" "+c.Path())
+	defer span.End()
+	// recoverPanics is outside this, so record panics on their way to it.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			span.RecordError(fmt.Errorf("panic: %v", recovered))
+			panic(recovered)
+		}
+	}()
+	span.SetAttribute("http.method", c.Method())
+	span.SetAttribute("http.target", c.OriginalURL())
+	span.SetAttribute("hx.boosted", c.Get("HX-Boosted") == "true")
+	span.SetAttribute("hx.request", c.Get("HX-Request") == "true")
+	c.SetUserContext(ctx)
+	c.Set("traceparent", span.Context().Traceparent())
+
+	err := c.Next()
+	span.RecordError(err)
+	span.SetName(c.Method() + " " + c.Route().Path)
+	span.SetAttribute("http.route", c.Route().Path)
+	span.SetAttribute("http.status_code", c.Response().StatusCode())
+	return err
+}
+
+// Wraps a handler in a span, so its time can be told apart from the time
+// spent routing and in middleware.
+func traced(handler fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, span := DefaultTracer.Start(c.UserContext(), "handler")
+		defer span.End()
+		span.SetAttribute("http.route", c.Route().Path)
+		defer c.SetUserContext(c.UserContext())
+		c.SetUserContext(ctx)
+		err := handler(c)
+		span.RecordError(err)
+		return err
+	}
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Code outside traceRequests has no span, and mustn't have to check.
+func TestNilSpan(t *testing.T) {
+	span := SpanFromContext(context.Background())
+	span.SetName("name")
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("failed"))
+	if span.Context() != (SpanContext{}) {
+		t.Errorf("got %v, want no span context", span.Context())
+	}
+	span.End()
+}
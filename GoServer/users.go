@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type User struct {
+	Name         string    `json:"name"`
+	PasswordHash []byte    `json:"passwordHash"`
+	Roles        []string  `json:"roles"`
+	Created      time.Time `json:"created"`
+}
+
+// Local user accounts, kept in memory and, when it has a path, saved to a
+// JSON file after every change.
+type UserStore struct {
+	mutex sync.RWMutex
+	path  string
+	users map[string]*User
+}
+
+var (
+	ErrUserExists     = errors.New("that user name is taken")
+	ErrBadCredentials = errors.New("wrong user name or password")
+	ErrNoSuchUser     = errors.New("no such user")
+	ErrBadUserName    = errors.New(
+		"user names are 3 to 32 letters, digits, dots, dashes or underscores")
+	ErrShortPassword = fmt.Errorf(
+		"passwords need at least %d characters", minPasswordLength)
+)
+
+const minPasswordLength = 8
+
+var validUserName = regexp.MustCompile(`^[A-Za-z0-9._-]{3,32}$`)
+
+// Compared against when a user doesn't exist, so logging in as nobody takes
+// as long as logging in with a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"),
+	bcrypt.DefaultCost)
+
+func NewUserStore(path string) (*UserStore, error) {
+	s := &UserStore{path: path, users: make(map[string]*User)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var users []*User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	for _, user := range users {
+		if len(user.Roles) == 0 {
+			user.Roles = []string{RoleMember}
+		}
+		s.users[user.Name] = user
+	}
+	return s, nil
+}
+
+func (s *UserStore) Get(name string) *User {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.users[name]
+}
+
+// All the users, sorted by name.
+func (s *UserStore) All() []*User {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	users := make([]*User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return users
+}
+
+func (s *UserStore) Register(name, password string) (*User, error) {
+	if !validUserName.MatchString(name) {
+		return nil, ErrBadUserName
+	}
+	if len(password) < minPasswordLength {
+		return nil, ErrShortPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password),
+		bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fiber's strings point into buffers that are reused after the
+	// request, so keep a copy.
+	name = strings.Clone(name)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.users[name] != nil {
+		return nil, ErrUserExists
+	}
+	user := &User{
+		Name:         name,
+		PasswordHash: hash,
+		Roles:        []string{RoleMember},
+		Created:      time.Now(),
+	}
+	s.users[name] = user
+	if err := s.save(); err != nil {
+		delete(s.users, name)
+		return nil, err
+	}
+	return user, nil
+}
+
+// Gives a user a role.  Nobody gets one by registering, so admins are made
+// this way, by likeBlazor adduser -admin.
+func (s *UserStore) Grant(name, role string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	user := s.users[name]
+	if user == nil {
+		return ErrNoSuchUser
+	}
+	for _, r := range user.Roles {
+		if r == role {
+			return nil
+		}
+	}
+	roles := user.Roles
+	user.Roles = append(roles[:len(roles):len(roles)], role)
+	if err := s.save(); err != nil {
+		user.Roles = roles
+		return err
+	}
+	return nil
+}
+
+func (s *UserStore) Authenticate(name, password string) (*User, error) {
+	user := s.Get(name)
+	hash := dummyHash
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
+	if user == nil || err != nil {
+		return nil, ErrBadCredentials
+	}
+	return user, nil
+}
+
+// Writes all the users to the store's file.  Callers hold the lock.
+func (s *UserStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	users := make([]*User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".users-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,32 @@
+package server_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"example/server/servertest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Admins come from likeBlazor adduser -admin, so whoever registers first
+// on a fresh site can't take it over.
+func TestFirstUserIsNoAdmin(t *testing.T) {
+	app := servertest.NewApp(t, stub)
+	form := url.Values{
+		"username": {"first"},
+		"password": {"password1"},
+		"confirm":  {"password1"},
+	}
+	resp := servertest.Request(t, app, fiber.MethodPost, "/register",
+		strings.NewReader(form.Encode()), nil, nil)
+	if resp.StatusCode != fiber.StatusSeeOther {
+		t.Fatalf("registering: got %s", resp.Status)
+	}
+	resp = servertest.Request(t, app, fiber.MethodGet, "/admin", nil, nil,
+		resp.Cookies())
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("/admin: got %s, want 403", resp.Status)
+	}
+}
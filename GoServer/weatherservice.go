@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type Forecast struct {
+	Date         string
+	TemperatureC int
+	TemperatureF int
+	Summary      string
+}
+
+var summaries = []string{
+	"Freezing", "Bracing", "Chilly", "Cool", "Mild", "Warm", "Balmy", "Hot", "Sweltering", "Scorching",
+}
+
+// Makes the forecasts for the days from startDate on.
+type ForecastProvider func(ctx context.Context, startDate time.Time) []Forecast
+
+func GetForecasts(ctx context.Context, startDate time.Time) []Forecast {
+	return MakeForecasts(ctx, startDate, rand.Intn)
+}
+
+// Makes forecasts with random numbers from intn, which returns a number
+// in [0, n) like rand.Intn.
+func MakeForecasts(ctx context.Context, startDate time.Time,
+	intn func(n int) int) []Forecast {
+	_, span := DefaultTracer.Start(ctx, "GetForecasts")
+	defer span.End()
+	forecasts := make([]Forecast, 5)
+	for i := 0; i < len(forecasts); i++ {
+		date := startDate.AddDate(0, 0, i)
+		forecasts[i].Date = fmt.Sprintf("%d/%d/%d",
+			date.Month(), date.Day(), date.Year())
+		forecasts[i].TemperatureC = intn(75) - 20
+		forecasts[i].TemperatureF = int(
+			32.0 + float32(forecasts[i].TemperatureC)/0.5556)
+		forecasts[i].Summary = summaries[intn(len(summaries))]
+	}
+	return forecasts
+}
+
+// Serves every client the same forecasts, computing them at most once per
+// ttl.  For another ttl after that, the old forecasts are still served,
+// and the first request for them starts computing new ones in the
+// background; nothing is computed while nobody asks.  Clients that ask for
+// forecasts nobody has yet all wait on one computation.  There's an entry
+// per day, since forecasts start on the day they're asked for, and days
+// too old to serve are swept out.
+type ForecastCache struct {
+	ttl     time.Duration
+	compute ForecastProvider
+
+	mutex   sync.Mutex
+	entries map[string]*forecastEntry
+}
+
+type forecastEntry struct {
+	forecasts  []Forecast
+	computed   time.Time
+	refreshing bool
+	// Closed once the first computation is done, or has failed.
+	ready  chan struct{}
+	failed bool
+}
+
+// A ttl of zero computes forecasts for every request.
+func NewForecastCache(ttl time.Duration,
+	compute ForecastProvider) *ForecastCache {
+	return &ForecastCache{
+		ttl:     ttl,
+		compute: compute,
+		entries: make(map[string]*forecastEntry),
+	}
+}
+
+func (fc *ForecastCache) Get(ctx context.Context, now time.Time) []Forecast {
+	if fc.ttl <= 0 {
+		return fc.compute(ctx, now)
+	}
+	span := SpanFromContext(ctx)
+	// Yesterday's forecasts start on the wrong day.
+	key := now.Format("2006-01-02")
+
+	fc.mutex.Lock()
+	entry := fc.entries[key]
+	if entry != nil && entry.isReady() && now.Sub(entry.computed) >= 2*fc.ttl {
+		entry = nil
+	}
+	if entry == nil {
+		fc.sweep(now)
+		entry = &forecastEntry{ready: make(chan struct{})}
+		fc.entries[key] = entry
+		fc.mutex.Unlock()
+		span.SetAttribute("forecastCache", "miss")
+		return fc.fill(ctx, key, entry, now)
+	}
+	if !entry.isReady() {
+		fc.mutex.Unlock()
+		span.SetAttribute("forecastCache", "wait")
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return nil
+		}
+		fc.mutex.Lock()
+		forecasts, failed := entry.forecasts, entry.failed
+		fc.mutex.Unlock()
+		if failed {
+			return fc.Get(ctx, now)
+		}
+		return forecasts
+	}
+	defer fc.mutex.Unlock()
+	if now.Sub(entry.computed) < fc.ttl {
+		span.SetAttribute("forecastCache", "hit")
+		return entry.forecasts
+	}
+	span.SetAttribute("forecastCache", "stale")
+	if !entry.refreshing {
+		entry.refreshing = true
+		go fc.refresh(entry, now)
+	}
+	return entry.forecasts
+}
+
+// Computes the forecasts of a new entry.  If compute panics, the entry is
+// dropped so the next request tries again, and whoever waits on it is
+// woken up either way.
+func (fc *ForecastCache) fill(ctx context.Context, key string,
+	entry *forecastEntry, now time.Time) []Forecast {
+	defer func() {
+		fc.mutex.Lock()
+		defer fc.mutex.Unlock()
+		if entry.computed.IsZero() {
+			entry.failed = true
+			if fc.entries[key] == entry {
+				delete(fc.entries, key)
+			}
+		}
+		close(entry.ready)
+	}()
+	forecasts := fc.compute(ctx, now)
+	fc.mutex.Lock()
+	entry.forecasts, entry.computed = forecasts, now
+	fc.mutex.Unlock()
+	return forecasts
+}
+
+func (fc *ForecastCache) refresh(entry *forecastEntry, now time.Time) {
+	ctx, span := DefaultTracer.Start(context.Background(), "refreshForecasts")
+	defer span.End()
+	forecasts := fc.compute(ctx, now)
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	entry.forecasts, entry.computed = forecasts, now
+	entry.refreshing = false
+}
+
+// Drops forecasts too stale to serve.  Callers hold the lock.
+func (fc *ForecastCache) sweep(now time.Time) {
+	for key, entry := range fc.entries {
+		if entry.isReady() && now.Sub(entry.computed) >= 2*fc.ttl {
+			delete(fc.entries, key)
+		}
+	}
+}
+
+func (e *forecastEntry) isReady() bool {
+	select {
+	case <-e.ready:
+		return true
+	default:
+		return false
+	}
+}
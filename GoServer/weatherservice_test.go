@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A ForecastProvider that counts its calls, putting the count in the
+// forecasts' summaries.  When release is set, calls wait on it.
+type countingProvider struct {
+	calls   atomic.Int32
+	release chan struct{}
+	done    chan struct{}
+}
+
+func newCountingProvider() *countingProvider {
+	return &countingProvider{done: make(chan struct{}, 10)}
+}
+
+func (p *countingProvider) compute(ctx context.Context,
+	startDate time.Time) []Forecast {
+	n := p.calls.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	defer func() { p.done <- struct{}{} }()
+	return []Forecast{{Date: startDate.Format("2006-01-02"),
+		Summary: strconv.Itoa(int(n))}}
+}
+
+// Forecasts computed outside a request have no span to be children of.
+func TestForecastCacheWithoutSpan(t *testing.T) {
+	fc := NewForecastCache(time.Minute, GetForecasts)
+	if got := fc.Get(context.Background(), time.Now()); len(got) == 0 {
+		t.Error("got no forecasts")
+	}
+}
+
+func TestForecastCacheSingleFlight(t *testing.T) {
+	p := newCountingProvider()
+	p.release = make(chan struct{})
+	fc := NewForecastCache(time.Minute, p.compute)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	got := make([][]Forecast, 10)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = fc.Get(context.Background(), now)
+		}(i)
+	}
+	for p.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(p.release)
+	wg.Wait()
+
+	if n := p.calls.Load(); n != 1 {
+		t.Errorf("computed forecasts %d times, want once", n)
+	}
+	for i, forecasts := range got {
+		if len(forecasts) != 1 || forecasts[0].Summary != "1" {
+			t.Errorf("client %d got %v", i, forecasts)
+		}
+	}
+}
+
+func TestForecastCacheStaleWhileRevalidate(t *testing.T) {
+	p := newCountingProvider()
+	ttl := time.Minute
+	fc := NewForecastCache(ttl, p.compute)
+	start := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	get := func(now time.Time) string {
+		return fc.Get(context.Background(), now)[0].Summary
+	}
+
+	if got := get(start); got != "1" {
+		t.Fatalf("first: got %s", got)
+	}
+	<-p.done
+	if got := get(start.Add(ttl / 2)); got != "1" || p.calls.Load() != 1 {
+		t.Errorf("fresh: got %s after %d calls", got, p.calls.Load())
+	}
+	// Stale forecasts are served while new ones are computed.
+	stale := start.Add(ttl + time.Second)
+	if got := get(stale); got != "1" {
+		t.Errorf("stale: got %s, want the old forecasts", got)
+	}
+	<-p.done
+	if got := get(stale); got != "2" || p.calls.Load() != 2 {
+		t.Errorf("refreshed: got %s after %d calls", got, p.calls.Load())
+	}
+	// Too stale to serve, so clients wait for new ones.
+	if got := get(stale.Add(2 * ttl)); got != "3" {
+		t.Errorf("expired: got %s", got)
+	}
+}
+
+func TestForecastCacheSweep(t *testing.T) {
+	p := newCountingProvider()
+	ttl := time.Minute
+	fc := NewForecastCache(ttl, p.compute)
+	day := time.Date(2023, time.October, 1, 23, 59, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		now := day.AddDate(0, 0, i)
+		forecasts := fc.Get(context.Background(), now)
+		if want := now.Format("2006-01-02"); forecasts[0].Date != want {
+			t.Errorf("day %d: forecasts start %s, want %s", i,
+				forecasts[0].Date, want)
+		}
+	}
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	if len(fc.entries) != 1 {
+		t.Errorf("kept %d days of forecasts, want 1", len(fc.entries))
+	}
+}
+
+// A computation that panics mustn't leave clients waiting on it forever.
+func TestForecastCachePanic(t *testing.T) {
+	p := newCountingProvider()
+	p.release = make(chan struct{})
+	fc := NewForecastCache(time.Minute, func(ctx context.Context,
+		startDate time.Time) []Forecast {
+		if p.calls.Load() == 0 {
+			p.calls.Add(1)
+			<-p.release
+			panic("no forecasts")
+		}
+		return p.compute(ctx, startDate)
+	})
+	now := time.Now()
+
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		fc.Get(context.Background(), now)
+	}()
+	for p.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Clients that give up stop waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := fc.Get(ctx, now); got != nil {
+		t.Errorf("cancelled client got %v", got)
+	}
+
+	got := make(chan []Forecast)
+	go func() { got <- fc.Get(context.Background(), now) }()
+	close(p.release)
+	if recovered := <-panicked; recovered == nil {
+		t.Error("the panic was lost")
+	}
+	if forecasts := <-got; len(forecasts) != 1 {
+		t.Errorf("waiting client got %v", forecasts)
+	}
+	if forecasts := fc.Get(context.Background(), now); len(forecasts) != 1 {
+		t.Errorf("next client got %v", forecasts)
+	}
+}
@@ -1,11 +1,13 @@
 module example/likeBlazor/v2
 
-go 1.21
+go 1.22
 
 require (
+	example/server v0.0.0
 	github.com/a-h/templ v0.2.334 // direct
 	github.com/gofiber/fiber/v2 v2.49.2
 	github.com/valyala/bytebufferpool v1.0.0
+	github.com/valyala/fasthttp v1.49.0
 )
 
 require (
@@ -16,7 +18,11 @@ require (
 	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/valyala/fasthttp v1.49.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
-	golang.org/x/sys v0.12.0 // indirect
+	go.etcd.io/bbolt v1.3.11 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
+
+replace example/server => ../GoServer
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"strings"
 	"time"
 
+	"example/server"
+
 	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -13,12 +18,17 @@ import (
 
 // Render Component.
 func RenderC(c *fiber.Ctx, component templ.Component) error {
+	ctx, span := server.DefaultTracer.Start(c.UserContext(), "RenderC")
+	defer span.End()
 	// Get new buffer from pool
 	buf := bytebufferpool.Get()
 	defer bytebufferpool.Put(buf)
-	if err := component.Render(c.Context(), buf); err != nil {
-		return fmt.Errorf("failed to render: %w", err)
+	if err := component.Render(ctx, buf); err != nil {
+		err = fmt.Errorf("failed to render: %w", err)
+		span.RecordError(err)
+		return err
 	}
+	span.SetAttribute("bytes", buf.Len())
 
 	c.Set("Content-Type", "text/html")
 	c.Context().SetBody(buf.Bytes())
@@ -26,11 +36,24 @@ func RenderC(c *fiber.Ctx, component templ.Component) error {
 	return nil
 }
 
-// Wraps with Layout.
-func RenderPage(c *fiber.Ctx, title string,
+// Wraps with Layout, titled from the route table.
+func RenderPage(c *fiber.Ctx, component templ.Component) error {
+	return renderPage(c, c.Route().Path, server.RouteTitle(c), component)
+}
+
+// Wraps with Layout, highlighting the nav item for path.
+func renderPage(c *fiber.Ctx, path string, title string,
 	component templ.Component) error {
+	ctx, span := server.DefaultTracer.Start(c.UserContext(), "RenderPage")
+	defer span.End()
+	span.SetAttribute("title", title)
+	defer c.SetUserContext(c.UserContext())
+	c.SetUserContext(ctx)
 	c.Vary("HX-Boosted")
-	main := mainLayout(navMenu(c.Route().Path), component)
+	main := mainLayout(
+		navMenu(path, server.CurrentSession(c).Username(),
+			server.CurrentAccess(c)),
+		server.Breadcrumbs(path), component)
 	headers := c.GetReqHeaders()
 	var whichLayout templ.Component
 	if headers["Hx-Boosted"] == "true" {
@@ -38,33 +61,170 @@ func RenderPage(c *fiber.Ctx, title string,
 	} else {
 		whichLayout = layout(title, main)
 	}
-	return RenderC(c, whichLayout)
+	err := RenderC(c, whichLayout)
+	span.RecordError(err)
+	return err
 }
 
-func main() {
-	app := fiber.New(fiber.Config{})
-	app.Use(logger.New())
-	app.Static("/", "./wwwroot")
+// A component to render when checking the templates.
+type namedComponent struct {
+	name      string
+	component templ.Component
+}
 
-	app.Get("/", func(c *fiber.Ctx) error {
-		return RenderPage(c, "Home", index())
-	})
-	app.Get("/about", func(c *fiber.Ctx) error {
-		return RenderPage(c, "About", about())
-	})
-	app.Get("/counter", func(c *fiber.Ctx) error {
-		return RenderPage(c, "Counter", counter(0))
-	})
-	app.Get("/increment", func(c *fiber.Ctx) error {
-		count := c.QueryInt("count", 0)
-		return RenderC(c, counter(count))
-	})
-	app.Get("/fetchdata", func(c *fiber.Ctx) error {
-		return RenderPage(c, "Weather forecast", fetchData())
-	})
-	app.Post("/forecasts", func(c *fiber.Ctx) error {
-		return RenderC(c, forecasts(getForecasts(time.Now())))
+// Every component in templates.templ, with data that takes each of its
+// branches.
+func checkedComponents() []namedComponent {
+	user := &server.User{Name: "check",
+		Roles:   []string{server.RoleMember, server.RoleAdmin},
+		Created: time.Now()}
+	access := server.Access{User: user}
+	form := server.AccountForm{Username: "check", Next: "/counter",
+		Error: "error"}
+	return []namedComponent{
+		{"layout", layout("Check", index())},
+		{"boostedLayout", boostedLayout("Check", index())},
+		{"mainLayout", mainLayout(navMenu("/admin/users", user.Name, access),
+			server.Breadcrumbs("/admin/users"), index())},
+		{"breadcrumb", breadcrumb(server.Breadcrumbs("/admin/users"))},
+		{"navItem", navItem(server.NavItem{Text: "Admin", Path: "/admin",
+			Icon: "cog", Active: true,
+			Items: []server.NavItem{{Text: "Users", Path: "/admin/users"}}})},
+		{"navMenu", navMenu("/", "", server.Access{})},
+		{"index", index()},
+		{"counter", counter(1)},
+		{"about", about()},
+		{"surveyPrompt", surveyPrompt("Check")},
+		{"fetchData", fetchData()},
+		{"forecasts", forecasts([]server.Forecast{{Date: "1/1/2024",
+			Summary: "Mild"}})},
+		{"errorPage", errorPage(server.ErrorInfo{Status: 500, Title: "Error",
+			Message: "error", Detail: "detail", RequestID: "check"})},
+		{"tooManyRequests", tooManyRequests("GET", "/increment", 1)},
+		{"tooManyRequests", tooManyRequests("POST", "/forecasts", 1)},
+		{"loginPage", loginPage(form)},
+		{"registerPage", registerPage(form)},
+		{"adminPage", adminPage([]server.Route{{Path: "/admin/users",
+			Title: "Users"}})},
+		{"usersPage", usersPage([]*server.User{user})},
+	}
+}
+
+// templ compiles the components into the binary, so there are no templates
+// to load, but route names are only strings, and urlFor panics at render
+// time on ones the table doesn't have.  So this renders every component,
+// turning panics into errors, for check and startup to fail on.
+func checkTemplates() error {
+	for _, c := range checkedComponents() {
+		if err := checkRender(c.component); err != nil {
+			return fmt.Errorf("rendering %s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+func checkRender(component templ.Component) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	return component.Render(context.Background(), io.Discard)
+}
+
+// Renders templ css classes in a style element tagged with the request's
+// CSP nonce.  templ's own <style> elements can't carry a nonce, so
+// components use the class names directly and render their styles with
+// this instead.
+func nonceStyle(classes ...templ.CSSClass) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		var css strings.Builder
+		for _, class := range classes {
+			if component, ok := class.(templ.ComponentCSSClass); ok {
+				css.WriteString(string(component.Class))
+			}
+		}
+		_, err := io.WriteString(w, `<style nonce="`+
+			templ.EscapeString(server.CSPNonce(ctx))+`">`+css.String()+
+			`</style>`)
+		return err
 	})
+}
 
-	log.Fatal(app.Listen(":3000"))
+// The templ components, as the server's views.
+type TemplViews struct{}
+
+// Components render when they're first asked for, so this checks them,
+// to fail at startup.  They're compiled in, so a hangup only empties the
+// page cache.
+func (TemplViews) Load() error {
+	return checkTemplates()
+}
+
+func (TemplViews) Index(c *fiber.Ctx) error {
+	return RenderPage(c, index())
+}
+
+func (TemplViews) About(c *fiber.Ctx) error {
+	return RenderPage(c, about())
+}
+
+func (TemplViews) Counter(c *fiber.Ctx, count int) error {
+	return RenderPage(c, counter(count))
+}
+
+func (TemplViews) CounterForm(c *fiber.Ctx, count int) error {
+	return RenderC(c, counter(count))
+}
+
+func (TemplViews) FetchData(c *fiber.Ctx) error {
+	return RenderPage(c, fetchData())
+}
+
+func (TemplViews) Forecasts(c *fiber.Ctx, list []server.Forecast) error {
+	return RenderC(c, forecasts(list))
+}
+
+func (TemplViews) Login(c *fiber.Ctx, form server.AccountForm) error {
+	return RenderPage(c, loginPage(form))
+}
+
+func (TemplViews) Register(c *fiber.Ctx, form server.AccountForm) error {
+	return RenderPage(c, registerPage(form))
+}
+
+func (TemplViews) Admin(c *fiber.Ctx, pages []server.Route) error {
+	return RenderPage(c, adminPage(pages))
+}
+
+func (TemplViews) Users(c *fiber.Ctx, users []*server.User) error {
+	return RenderPage(c, usersPage(users))
+}
+
+func (TemplViews) Error(c *fiber.Ctx, info server.ErrorInfo,
+	fragment bool) error {
+	if fragment {
+		return RenderC(c, errorPage(info))
+	}
+	return renderPage(c, c.Path(), info.Title, errorPage(info))
+}
+
+func (TemplViews) TooManyRequests(c *fiber.Ctx, method, url string,
+	retryAfter int) error {
+	return RenderC(c, tooManyRequests(method, url, retryAfter))
+}
+
+var frontend = &server.Frontend{
+	Views: TemplViews{},
+	Middleware: []fiber.Handler{
+		// Log to stderr, like the log package, leaving stdout to commands.
+		logger.New(logger.Config{
+			Format: "[${time}] ${status} - ${latency} ${method} ${path} ${locals:requestid}\n",
+			Output: os.Stderr,
+		}),
+	},
+}
+
+func main() {
+	frontend.Main()
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example/server"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Benchmarks of rendering every page and fragment, without HTTP in the
+// way.  GoApp has the same benchmarks, under the same names, for
+// MyViews.Render.
+
+var benchmarkDate = time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+var benchmarkUsers = []*server.User{
+	{Name: "ada", Roles: []string{server.RoleMember, server.RoleAdmin}},
+	{Name: "grace", Roles: []string{server.RoleMember}},
+	{Name: "linus", Roles: []string{server.RoleMember}},
+}
+
+// The pages under /admin that benchmarkUsers[0] may see.
+var benchmarkAdminPages = []server.Route{
+	{Path: "/admin/cache", Title: "Page cache"},
+	{Path: "/admin/users", Title: "Users"},
+}
+
+// A request for path as a logged in admin, who sees every nav item.
+func benchmarkCtx(b *testing.B, path string, boosted bool) *fiber.Ctx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	if boosted {
+		ctx.Request.Header.Set("HX-Request", "true")
+		ctx.Request.Header.Set("HX-Boosted", "true")
+	}
+	app := fiber.New()
+	c := app.AcquireCtx(ctx)
+	b.Cleanup(func() { app.ReleaseCtx(c) })
+	c.Locals("user", benchmarkUsers[0])
+	c.SetUserContext(server.WithCSPNonce(context.Background(),
+		"r4nd0mN0nceF0rBenchm4=="))
+	return c
+}
+
+type benchmarkPage struct {
+	name      string
+	path      string
+	title     string
+	component templ.Component
+}
+
+func benchmarkPages() []benchmarkPage {
+	return []benchmarkPage{
+		{"Index", "/", "Home", index()},
+		{"About", "/about", "About", about()},
+		{"Counter", "/counter", "Counter", counter(42)},
+		{"FetchData", "/fetchdata", "Weather forecast", fetchData()},
+		{"Login", "/login", "Log in",
+			loginPage(server.AccountForm{Next: "/counter"})},
+		{"Register", "/register", "Register", registerPage(server.AccountForm{})},
+		{"Admin", "/admin", "Admin", adminPage(benchmarkAdminPages)},
+		{"Users", "/admin/users", "Users", usersPage(benchmarkUsers)},
+		{"Error", "/missing", "Not Found", errorPage(benchmarkError)},
+	}
+}
+
+var benchmarkError = server.ErrorInfo{
+	Status:    fiber.StatusNotFound,
+	Title:     "Not Found",
+	Message:   "Sorry, there's nothing at this address.",
+	RequestID: "0b5e6a0e-6b1e-4d6c-9a43-2b1f0d1c3e55",
+}
+
+func BenchmarkPage(b *testing.B) {
+	for _, page := range benchmarkPages() {
+		for _, boosted := range []bool{false, true} {
+			name := page.name
+			if boosted {
+				name += "/boosted"
+			}
+			b.Run(name, func(b *testing.B) {
+				c := benchmarkCtx(b, page.path, boosted)
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := renderPage(c, page.path, page.title,
+						page.component); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.SetBytes(int64(len(c.Response().Body())))
+			})
+		}
+	}
+}
+
+func BenchmarkFragment(b *testing.B) {
+	fragments := []struct {
+		name      string
+		component templ.Component
+	}{
+		{"Counter", counter(42)},
+		{"Forecasts", forecasts(server.GetForecasts(context.Background(),
+			benchmarkDate))},
+		{"TooManyRequests", tooManyRequests(fiber.MethodPost,
+			"/forecasts", 3)},
+		{"Error", errorPage(benchmarkError)},
+	}
+	for _, fragment := range fragments {
+		b.Run(fragment.name, func(b *testing.B) {
+			c := benchmarkCtx(b, "/", false)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := RenderC(c, fragment.component); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.SetBytes(int64(len(c.Response().Body())))
+		})
+	}
+}
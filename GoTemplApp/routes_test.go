@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"testing"
+
+	"example/server"
+	"example/server/servertest"
+
+	"github.com/a-h/templ"
+)
+
+// The tests every app runs, against these views.  Run go test -update to
+// rewrite the golden files after changing a page on purpose, then review
+// the diff.
+
+func TestGoldenRoutes(t *testing.T) {
+	servertest.GoldenRoutes(t, frontend)
+}
+
+func TestAccessibility(t *testing.T) {
+	servertest.Accessibility(t, frontend)
+}
+
+func TestRegisterAndCount(t *testing.T) {
+	servertest.RegisterAndCount(t, frontend)
+}
+
+func TestBoostedNavigation(t *testing.T) {
+	servertest.BoostedNavigation(t, frontend)
+}
+
+func TestCheckTemplates(t *testing.T) {
+	if err := checkTemplates(); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, server.URLFor("nowhere"))
+		return err
+	})
+	if err := checkRender(bad); err == nil {
+		t.Errorf("rendered a link to a route that doesn't exist")
+	}
+
+	// A component left out of checkedComponents goes unchecked.
+	source, err := os.ReadFile("templates.templ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := make(map[string]bool)
+	for _, c := range checkedComponents() {
+		checked[c.name] = true
+	}
+	for _, match := range regexp.MustCompile(`(?m)^templ (\w+)\(`).
+		FindAllSubmatch(source, -1) {
+		if name := string(match[1]); !checked[name] {
+			t.Errorf("checkedComponents doesn't render %s", name)
+		}
+	}
+}
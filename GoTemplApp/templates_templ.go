@@ -10,7 +10,11 @@ import "io"
 import "bytes"
 import "strings"
 
-import "strconv"
+import (
+	"strconv"
+
+	"example/server"
+)
 
 func layout(title string, main templ.Component) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
@@ -25,7 +29,39 @@ func layout(title string, main templ.Component) templ.Component {
 			var_1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, err = templBuffer.WriteString("<!doctype html><html lang=\"en\"><head><meta charset=\"utf-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><base href=\"~/\"><link rel=\"stylesheet\" href=\"/css/bootstrap/bootstrap.min.css\"><link rel=\"stylesheet\" href=\"/css/open-iconic/font/css/open-iconic-bootstrap.min.css\"><link href=\"/css/BlazorApp.styles.css\" rel=\"stylesheet\"><title>")
+		_, err = templBuffer.WriteString("<!doctype html><html lang=\"en\"><head><meta charset=\"utf-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><meta name=\"htmx-config\" content=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.HTMXConfig(ctx)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\"><base href=\"/\"><link rel=\"stylesheet\" href=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.Asset("/css/bootstrap/bootstrap.min.css")))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\"><link rel=\"stylesheet\" href=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.Asset("/css/open-iconic/font/css/open-iconic-bootstrap.min.css")))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\"><link href=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.Asset("/css/BlazorApp.styles.css")))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" rel=\"stylesheet\"><title>")
 		if err != nil {
 			return err
 		}
@@ -42,7 +78,23 @@ func layout(title string, main templ.Component) templ.Component {
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString("</div><script src=\"/htmx1.9.6.min.js\">")
+		_, err = templBuffer.WriteString("</div><script src=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.Asset("/htmx1.9.6.min.js")))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" nonce=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.CSPNonce(ctx)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\">")
 		if err != nil {
 			return err
 		}
@@ -51,6 +103,56 @@ func layout(title string, main templ.Component) templ.Component {
 		if err != nil {
 			return err
 		}
+		_, err = templBuffer.WriteString("</script><script src=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.Asset("/js/errors.js")))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" nonce=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.CSPNonce(ctx)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\">")
+		if err != nil {
+			return err
+		}
+		var_4 := ``
+		_, err = templBuffer.WriteString(var_4)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</script><script src=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.Asset("/js/csp.js")))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" nonce=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.CSPNonce(ctx)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\">")
+		if err != nil {
+			return err
+		}
+		var_5 := ``
+		_, err = templBuffer.WriteString(var_5)
+		if err != nil {
+			return err
+		}
 		_, err = templBuffer.WriteString("</script></body></html>")
 		if err != nil {
 			return err
@@ -70,17 +172,17 @@ func boostedLayout(title string, main templ.Component) templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_4 := templ.GetChildren(ctx)
-		if var_4 == nil {
-			var_4 = templ.NopComponent
+		var_6 := templ.GetChildren(ctx)
+		if var_6 == nil {
+			var_6 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, err = templBuffer.WriteString("<title hx-swap-oob=\"title\">")
 		if err != nil {
 			return err
 		}
-		var var_5 string = title
-		_, err = templBuffer.WriteString(templ.EscapeString(var_5))
+		var var_7 string = title
+		_, err = templBuffer.WriteString(templ.EscapeString(var_7))
 		if err != nil {
 			return err
 		}
@@ -99,7 +201,7 @@ func boostedLayout(title string, main templ.Component) templ.Component {
 	})
 }
 
-func mainLayout(navMenu, mainArticle templ.Component) templ.Component {
+func mainLayout(navMenu templ.Component, crumbs []server.Crumb, mainArticle templ.Component) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
 		templBuffer, templIsBuffer := w.(*bytes.Buffer)
 		if !templIsBuffer {
@@ -107,9 +209,9 @@ func mainLayout(navMenu, mainArticle templ.Component) templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_6 := templ.GetChildren(ctx)
-		if var_6 == nil {
-			var_6 = templ.NopComponent
+		var_8 := templ.GetChildren(ctx)
+		if var_8 == nil {
+			var_8 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, err = templBuffer.WriteString("<div class=\"page\"><div class=\"sidebar\">")
@@ -120,12 +222,31 @@ func mainLayout(navMenu, mainArticle templ.Component) templ.Component {
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString("</div><main><div class=\"top-row px-4\"><a href=\"/about\" hx-boost=\"true\" hx-target=\"#main-layout\">")
+		_, err = templBuffer.WriteString("</div><main><div class=\"top-row px-4\">")
+		if err != nil {
+			return err
+		}
+		if len(crumbs) > 0 {
+			err = breadcrumb(crumbs).Render(ctx, templBuffer)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("<a href=\"")
+		if err != nil {
+			return err
+		}
+		var var_9 templ.SafeURL = templ.SafeURL(server.URLFor(server.RouteAbout))
+		_, err = templBuffer.WriteString(templ.EscapeString(string(var_9)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" hx-boost=\"true\" hx-target=\"#main-layout\">")
 		if err != nil {
 			return err
 		}
-		var_7 := `About`
-		_, err = templBuffer.WriteString(var_7)
+		var_10 := `About`
+		_, err = templBuffer.WriteString(var_10)
 		if err != nil {
 			return err
 		}
@@ -148,15 +269,14 @@ func mainLayout(navMenu, mainArticle templ.Component) templ.Component {
 	})
 }
 
-func navLinkClass(requestPath string, href string) string {
-	if href == requestPath {
+func navLinkClass(item server.NavItem) string {
+	if item.Active {
 		return "nav-link active"
-	} else {
-		return "nav-link"
 	}
+	return "nav-link"
 }
 
-func navItem(requestPath string, text string, href string, oiIcon string) templ.Component {
+func breadcrumb(crumbs []server.Crumb) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
 		templBuffer, templIsBuffer := w.(*bytes.Buffer)
 		if !templIsBuffer {
@@ -164,17 +284,85 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_8 := templ.GetChildren(ctx)
-		if var_8 == nil {
-			var_8 = templ.NopComponent
+		var_11 := templ.GetChildren(ctx)
+		if var_11 == nil {
+			var_11 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, err = templBuffer.WriteString("<nav aria-label=\"Breadcrumb\" class=\"me-auto\"><ol class=\"breadcrumb mb-0\">")
+		if err != nil {
+			return err
+		}
+		for _, crumb := range crumbs {
+			if crumb.Path != "" {
+				_, err = templBuffer.WriteString("<li class=\"breadcrumb-item\"><a href=\"")
+				if err != nil {
+					return err
+				}
+				var var_12 templ.SafeURL = templ.SafeURL(crumb.Path)
+				_, err = templBuffer.WriteString(templ.EscapeString(string(var_12)))
+				if err != nil {
+					return err
+				}
+				_, err = templBuffer.WriteString("\">")
+				if err != nil {
+					return err
+				}
+				var var_13 string = crumb.Text
+				_, err = templBuffer.WriteString(templ.EscapeString(var_13))
+				if err != nil {
+					return err
+				}
+				_, err = templBuffer.WriteString("</a></li>")
+				if err != nil {
+					return err
+				}
+			} else {
+				_, err = templBuffer.WriteString("<li class=\"breadcrumb-item active\" aria-current=\"page\">")
+				if err != nil {
+					return err
+				}
+				var var_14 string = crumb.Text
+				_, err = templBuffer.WriteString(templ.EscapeString(var_14))
+				if err != nil {
+					return err
+				}
+				_, err = templBuffer.WriteString("</li>")
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = templBuffer.WriteString("</ol></nav>")
+		if err != nil {
+			return err
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func navItem(item server.NavItem) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_15 := templ.GetChildren(ctx)
+		if var_15 == nil {
+			var_15 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, err = templBuffer.WriteString("<div class=\"nav-item px-3\">")
 		if err != nil {
 			return err
 		}
-		var var_9 = []any{navLinkClass(requestPath, href)}
-		err = templ.RenderCSSItems(ctx, templBuffer, var_9...)
+		var var_16 = []any{navLinkClass(item)}
+		err = templ.RenderCSSItems(ctx, templBuffer, var_16...)
 		if err != nil {
 			return err
 		}
@@ -182,7 +370,7 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_9).String()))
+		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_16).String()))
 		if err != nil {
 			return err
 		}
@@ -190,8 +378,8 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 		if err != nil {
 			return err
 		}
-		var var_10 templ.SafeURL = templ.SafeURL(href)
-		_, err = templBuffer.WriteString(templ.EscapeString(string(var_10)))
+		var var_17 templ.SafeURL = templ.SafeURL(item.Path)
+		_, err = templBuffer.WriteString(templ.EscapeString(string(var_17)))
 		if err != nil {
 			return err
 		}
@@ -199,8 +387,8 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 		if err != nil {
 			return err
 		}
-		var var_11 = []any{"oi oi-" + oiIcon}
-		err = templ.RenderCSSItems(ctx, templBuffer, var_11...)
+		var var_18 = []any{"oi oi-" + item.Icon}
+		err = templ.RenderCSSItems(ctx, templBuffer, var_18...)
 		if err != nil {
 			return err
 		}
@@ -208,7 +396,7 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_11).String()))
+		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_18).String()))
 		if err != nil {
 			return err
 		}
@@ -216,12 +404,66 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 		if err != nil {
 			return err
 		}
-		var var_12 string = text
-		_, err = templBuffer.WriteString(templ.EscapeString(var_12))
+		var var_19 string = item.Text
+		_, err = templBuffer.WriteString(templ.EscapeString(var_19))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</a>")
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString("</a></div>")
+		if len(item.Items) > 0 {
+			_, err = templBuffer.WriteString("<input type=\"checkbox\" id=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString(item.MenuID()))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\" class=\"submenu-toggle visually-hidden\" aria-label=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString(item.Text + " menu"))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\"")
+			if err != nil {
+				return err
+			}
+			if item.Active {
+				_, err = templBuffer.WriteString(" checked")
+				if err != nil {
+					return err
+				}
+			}
+			_, err = templBuffer.WriteString("> <label for=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString(item.MenuID()))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\" class=\"submenu-label\"><span class=\"oi oi-chevron-bottom\" aria-hidden=\"true\"></span></label> <div class=\"submenu\">")
+			if err != nil {
+				return err
+			}
+			for _, child := range item.Items {
+				err = navItem(child).Render(ctx, templBuffer)
+				if err != nil {
+					return err
+				}
+			}
+			_, err = templBuffer.WriteString("</div>")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("</div>")
 		if err != nil {
 			return err
 		}
@@ -232,7 +474,7 @@ func navItem(requestPath string, text string, href string, oiIcon string) templ.
 	})
 }
 
-func navMenu(path string) templ.Component {
+func navMenu(path string, user string, access server.Access) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
 		templBuffer, templIsBuffer := w.(*bytes.Buffer)
 		if !templIsBuffer {
@@ -240,35 +482,61 @@ func navMenu(path string) templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_13 := templ.GetChildren(ctx)
-		if var_13 == nil {
-			var_13 = templ.NopComponent
+		var_20 := templ.GetChildren(ctx)
+		if var_20 == nil {
+			var_20 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, err = templBuffer.WriteString("<div class=\"navbar-top-row ps-3 navbar navbar-dark\"><div class=\"container-fluid\"><a class=\"navbar-brand\" href=\"\">")
+		_, err = templBuffer.WriteString("<div class=\"navbar-top-row ps-3 navbar navbar-dark\"><div class=\"container-fluid\"><a class=\"navbar-brand\" href=\"")
 		if err != nil {
 			return err
 		}
-		var_14 := `BlazorApp`
-		_, err = templBuffer.WriteString(var_14)
+		var var_21 templ.SafeURL = templ.SafeURL(server.URLFor(server.RouteHome))
+		_, err = templBuffer.WriteString(templ.EscapeString(string(var_21)))
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString("</a><label for=\"toggle-menu\"><div title=\"Navigation menu\" class=\"navbar-toggler\"><span class=\"navbar-toggler-icon\"></span></div></label></div></div><input type=\"checkbox\" id=\"toggle-menu\" class=\"visually-hidden\"><div id=\"nav-menu\"><nav class=\"flex-column\" hx-boost=\"true\" hx-target=\"#main-layout\">")
+		_, err = templBuffer.WriteString("\">")
 		if err != nil {
 			return err
 		}
-		err = navItem(path, "Home", "/", "home").Render(ctx, templBuffer)
+		var_22 := `BlazorApp`
+		_, err = templBuffer.WriteString(var_22)
 		if err != nil {
 			return err
 		}
-		err = navItem(path, "Counter", "/counter", "plus").Render(ctx, templBuffer)
+		_, err = templBuffer.WriteString("</a><label for=\"toggle-menu\"><div title=\"Navigation menu\" class=\"navbar-toggler\"><span class=\"navbar-toggler-icon\"></span></div></label></div></div><input type=\"checkbox\" id=\"toggle-menu\" class=\"visually-hidden\"><div id=\"nav-menu\"><nav class=\"flex-column\" hx-boost=\"true\" hx-target=\"#main-layout\">")
 		if err != nil {
 			return err
 		}
-		err = navItem(path, "Fetch data", "/fetchdata", "list-rich").Render(ctx, templBuffer)
-		if err != nil {
-			return err
+		for _, item := range server.NavItems(access, path) {
+			err = navItem(item).Render(ctx, templBuffer)
+			if err != nil {
+				return err
+			}
+		}
+		if user != "" {
+			_, err = templBuffer.WriteString("<div class=\"nav-item px-3\"><form method=\"post\" action=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString(server.URLFor(server.RouteLogout)))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\"><button type=\"submit\" class=\"nav-link btn btn-link\"><span class=\"oi oi-account-logout\" aria-hidden=\"true\"></span>")
+			if err != nil {
+				return err
+			}
+			var var_23 string = "Log out " + user
+			_, err = templBuffer.WriteString(templ.EscapeString(var_23))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</button></form></div>")
+			if err != nil {
+				return err
+			}
 		}
 		_, err = templBuffer.WriteString("</nav></div>")
 		if err != nil {
@@ -289,17 +557,17 @@ func index() templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_15 := templ.GetChildren(ctx)
-		if var_15 == nil {
-			var_15 = templ.NopComponent
+		var_24 := templ.GetChildren(ctx)
+		if var_24 == nil {
+			var_24 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, err = templBuffer.WriteString("<h1>")
 		if err != nil {
 			return err
 		}
-		var_16 := `Hello, world!`
-		_, err = templBuffer.WriteString(var_16)
+		var_25 := `Hello, world!`
+		_, err = templBuffer.WriteString(var_25)
 		if err != nil {
 			return err
 		}
@@ -307,8 +575,8 @@ func index() templ.Component {
 		if err != nil {
 			return err
 		}
-		var_17 := `Welcome to your new app.`
-		_, err = templBuffer.WriteString(var_17)
+		var_26 := `Welcome to your new app.`
+		_, err = templBuffer.WriteString(var_26)
 		if err != nil {
 			return err
 		}
@@ -335,17 +603,25 @@ func counter(count int) templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_18 := templ.GetChildren(ctx)
-		if var_18 == nil {
-			var_18 = templ.NopComponent
+		var_27 := templ.GetChildren(ctx)
+		if var_27 == nil {
+			var_27 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, err = templBuffer.WriteString("<form id=\"increment-form\" hx-get=\"/increment\" hx-swap=\"outerHTML\"><h1>")
+		_, err = templBuffer.WriteString("<form id=\"increment-form\" hx-get=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.URLFor(server.RouteIncrement)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" hx-swap=\"outerHTML\"><h1>")
 		if err != nil {
 			return err
 		}
-		var_19 := `Counter`
-		_, err = templBuffer.WriteString(var_19)
+		var_28 := `Counter`
+		_, err = templBuffer.WriteString(var_28)
 		if err != nil {
 			return err
 		}
@@ -353,13 +629,13 @@ func counter(count int) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_20 := `Current count: `
-		_, err = templBuffer.WriteString(var_20)
+		var_29 := `Current count: `
+		_, err = templBuffer.WriteString(var_29)
 		if err != nil {
 			return err
 		}
-		var var_21 string = strconv.Itoa(count)
-		_, err = templBuffer.WriteString(templ.EscapeString(var_21))
+		var var_30 string = strconv.Itoa(count)
+		_, err = templBuffer.WriteString(templ.EscapeString(var_30))
 		if err != nil {
 			return err
 		}
@@ -403,17 +679,30 @@ func about() templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_22 := templ.GetChildren(ctx)
-		if var_22 == nil {
-			var_22 = templ.NopComponent
+		var_31 := templ.GetChildren(ctx)
+		if var_31 == nil {
+			var_31 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, err = templBuffer.WriteString("<p>")
+		err = nonceStyle(bigLink()).Render(ctx, templBuffer)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("<h1>")
+		if err != nil {
+			return err
+		}
+		var_32 := `About`
+		_, err = templBuffer.WriteString(var_32)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</h1><p>")
 		if err != nil {
 			return err
 		}
-		var_23 := `I'm built with`
-		_, err = templBuffer.WriteString(var_23)
+		var_33 := `I'm built with`
+		_, err = templBuffer.WriteString(var_33)
 		if err != nil {
 			return err
 		}
@@ -421,8 +710,8 @@ func about() templ.Component {
 		if err != nil {
 			return err
 		}
-		var var_24 = []any{bigLink}
-		err = templ.RenderCSSItems(ctx, templBuffer, var_24...)
+		var var_34 = []any{bigLink().ClassName()}
+		err = templ.RenderCSSItems(ctx, templBuffer, var_34...)
 		if err != nil {
 			return err
 		}
@@ -430,7 +719,7 @@ func about() templ.Component {
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_24).String()))
+		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_34).String()))
 		if err != nil {
 			return err
 		}
@@ -438,8 +727,8 @@ func about() templ.Component {
 		if err != nil {
 			return err
 		}
-		var_25 := `Go Fiber`
-		_, err = templBuffer.WriteString(var_25)
+		var_35 := `Go Fiber`
+		_, err = templBuffer.WriteString(var_35)
 		if err != nil {
 			return err
 		}
@@ -447,8 +736,8 @@ func about() templ.Component {
 		if err != nil {
 			return err
 		}
-		var var_26 = []any{bigLink}
-		err = templ.RenderCSSItems(ctx, templBuffer, var_26...)
+		var var_36 = []any{bigLink().ClassName()}
+		err = templ.RenderCSSItems(ctx, templBuffer, var_36...)
 		if err != nil {
 			return err
 		}
@@ -456,7 +745,7 @@ func about() templ.Component {
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_26).String()))
+		_, err = templBuffer.WriteString(templ.EscapeString(templ.CSSClasses(var_36).String()))
 		if err != nil {
 			return err
 		}
@@ -464,8 +753,8 @@ func about() templ.Component {
 		if err != nil {
 			return err
 		}
-		var_27 := `HTMX`
-		_, err = templBuffer.WriteString(var_27)
+		var_37 := `HTMX`
+		_, err = templBuffer.WriteString(var_37)
 		if err != nil {
 			return err
 		}
@@ -488,17 +777,17 @@ func surveyPrompt(title string) templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_28 := templ.GetChildren(ctx)
-		if var_28 == nil {
-			var_28 = templ.NopComponent
+		var_38 := templ.GetChildren(ctx)
+		if var_38 == nil {
+			var_38 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, err = templBuffer.WriteString("<div class=\"alert alert-secondary mt-4\"><span class=\"oi oi-pencil me-2\" aria-hidden=\"true\"></span><strong>")
 		if err != nil {
 			return err
 		}
-		var var_29 string = title
-		_, err = templBuffer.WriteString(templ.EscapeString(var_29))
+		var var_39 string = title
+		_, err = templBuffer.WriteString(templ.EscapeString(var_39))
 		if err != nil {
 			return err
 		}
@@ -506,8 +795,8 @@ func surveyPrompt(title string) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_30 := `Please take our`
-		_, err = templBuffer.WriteString(var_30)
+		var_40 := `Please take our`
+		_, err = templBuffer.WriteString(var_40)
 		if err != nil {
 			return err
 		}
@@ -515,8 +804,8 @@ func surveyPrompt(title string) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_31 := `brief survey`
-		_, err = templBuffer.WriteString(var_31)
+		var_41 := `brief survey`
+		_, err = templBuffer.WriteString(var_41)
 		if err != nil {
 			return err
 		}
@@ -524,8 +813,8 @@ func surveyPrompt(title string) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_32 := `and tell us what you think.`
-		_, err = templBuffer.WriteString(var_32)
+		var_42 := `and tell us what you think.`
+		_, err = templBuffer.WriteString(var_42)
 		if err != nil {
 			return err
 		}
@@ -548,17 +837,17 @@ func fetchData() templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_33 := templ.GetChildren(ctx)
-		if var_33 == nil {
-			var_33 = templ.NopComponent
+		var_43 := templ.GetChildren(ctx)
+		if var_43 == nil {
+			var_43 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		_, err = templBuffer.WriteString("<h1>")
 		if err != nil {
 			return err
 		}
-		var_34 := `Weather forecast`
-		_, err = templBuffer.WriteString(var_34)
+		var_44 := `Weather forecast`
+		_, err = templBuffer.WriteString(var_44)
 		if err != nil {
 			return err
 		}
@@ -566,17 +855,25 @@ func fetchData() templ.Component {
 		if err != nil {
 			return err
 		}
-		var_35 := `This component demonstrates fetching data from a service.`
-		_, err = templBuffer.WriteString(var_35)
+		var_45 := `This component demonstrates fetching data from a service.`
+		_, err = templBuffer.WriteString(var_45)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</p><p hx-trigger=\"every 2s\" hx-post=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.URLFor(server.RouteForecasts)))
 		if err != nil {
 			return err
 		}
-		_, err = templBuffer.WriteString("</p><p hx-trigger=\"every 2s\" hx-post=\"/forecasts\" hx-swap=\"outerHTML\"><em>")
+		_, err = templBuffer.WriteString("\" hx-swap=\"outerHTML\"><em>")
 		if err != nil {
 			return err
 		}
-		var_36 := `Loading...`
-		_, err = templBuffer.WriteString(var_36)
+		var_46 := `Loading...`
+		_, err = templBuffer.WriteString(var_46)
 		if err != nil {
 			return err
 		}
@@ -591,7 +888,7 @@ func fetchData() templ.Component {
 	})
 }
 
-func forecasts(forecasts []Forecast) templ.Component {
+func forecasts(forecasts []server.Forecast) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
 		templBuffer, templIsBuffer := w.(*bytes.Buffer)
 		if !templIsBuffer {
@@ -599,17 +896,25 @@ func forecasts(forecasts []Forecast) templ.Component {
 			defer templ.ReleaseBuffer(templBuffer)
 		}
 		ctx = templ.InitializeContext(ctx)
-		var_37 := templ.GetChildren(ctx)
-		if var_37 == nil {
-			var_37 = templ.NopComponent
+		var_47 := templ.GetChildren(ctx)
+		if var_47 == nil {
+			var_47 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, err = templBuffer.WriteString("<table class=\"table\" hx-trigger=\"every 2s\" hx-post=\"/forecasts\" hx-swap=\"outerHTML\"><thead><tr><th>")
+		_, err = templBuffer.WriteString("<table class=\"table\" hx-trigger=\"every 2s\" hx-post=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.URLFor(server.RouteForecasts)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" hx-swap=\"outerHTML\"><thead><tr><th>")
 		if err != nil {
 			return err
 		}
-		var_38 := `Date`
-		_, err = templBuffer.WriteString(var_38)
+		var_48 := `Date`
+		_, err = templBuffer.WriteString(var_48)
 		if err != nil {
 			return err
 		}
@@ -617,8 +922,8 @@ func forecasts(forecasts []Forecast) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_39 := `Temp. (C)`
-		_, err = templBuffer.WriteString(var_39)
+		var_49 := `Temp. (C)`
+		_, err = templBuffer.WriteString(var_49)
 		if err != nil {
 			return err
 		}
@@ -626,8 +931,8 @@ func forecasts(forecasts []Forecast) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_40 := `Temp. (F)`
-		_, err = templBuffer.WriteString(var_40)
+		var_50 := `Temp. (F)`
+		_, err = templBuffer.WriteString(var_50)
 		if err != nil {
 			return err
 		}
@@ -635,8 +940,8 @@ func forecasts(forecasts []Forecast) templ.Component {
 		if err != nil {
 			return err
 		}
-		var_41 := `Summary`
-		_, err = templBuffer.WriteString(var_41)
+		var_51 := `Summary`
+		_, err = templBuffer.WriteString(var_51)
 		if err != nil {
 			return err
 		}
@@ -649,8 +954,26 @@ func forecasts(forecasts []Forecast) templ.Component {
 			if err != nil {
 				return err
 			}
-			var var_42 string = forecast.Date
-			_, err = templBuffer.WriteString(templ.EscapeString(var_42))
+			var var_52 string = forecast.Date
+			_, err = templBuffer.WriteString(templ.EscapeString(var_52))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</td><td>")
+			if err != nil {
+				return err
+			}
+			var var_53 string = strconv.Itoa(forecast.TemperatureC)
+			_, err = templBuffer.WriteString(templ.EscapeString(var_53))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</td><td>")
+			if err != nil {
+				return err
+			}
+			var var_54 string = strconv.Itoa(forecast.TemperatureF)
+			_, err = templBuffer.WriteString(templ.EscapeString(var_54))
 			if err != nil {
 				return err
 			}
@@ -658,8 +981,567 @@ func forecasts(forecasts []Forecast) templ.Component {
 			if err != nil {
 				return err
 			}
-			var var_43 string = strconv.Itoa(forecast.TemperatureC)
-			_, err = templBuffer.WriteString(templ.EscapeString(var_43))
+			var var_55 string = forecast.Summary
+			_, err = templBuffer.WriteString(templ.EscapeString(var_55))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</td></tr>")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("</tbody></table>")
+		if err != nil {
+			return err
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func errorPage(e server.ErrorInfo) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_56 := templ.GetChildren(ctx)
+		if var_56 == nil {
+			var_56 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, err = templBuffer.WriteString("<div class=\"alert alert-danger\" role=\"alert\"><h1>")
+		if err != nil {
+			return err
+		}
+		var var_57 string = strconv.Itoa(e.Status) + " " + e.Title
+		_, err = templBuffer.WriteString(templ.EscapeString(var_57))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</h1><p>")
+		if err != nil {
+			return err
+		}
+		var var_58 string = e.Message
+		_, err = templBuffer.WriteString(templ.EscapeString(var_58))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</p>")
+		if err != nil {
+			return err
+		}
+		if e.Detail != "" {
+			_, err = templBuffer.WriteString("<pre>")
+			if err != nil {
+				return err
+			}
+			var var_59 string = e.Detail
+			_, err = templBuffer.WriteString(templ.EscapeString(var_59))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</pre>")
+			if err != nil {
+				return err
+			}
+		}
+		if e.RequestID != "" {
+			_, err = templBuffer.WriteString("<p class=\"small mb-0\">")
+			if err != nil {
+				return err
+			}
+			var_60 := `Request ID: `
+			_, err = templBuffer.WriteString(var_60)
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("<code>")
+			if err != nil {
+				return err
+			}
+			var var_61 string = e.RequestID
+			_, err = templBuffer.WriteString(templ.EscapeString(var_61))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</code></p>")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("</div>")
+		if err != nil {
+			return err
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func tooManyRequests(method string, url string, retryAfter int) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_62 := templ.GetChildren(ctx)
+		if var_62 == nil {
+			var_62 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		if method == "POST" {
+			_, err = templBuffer.WriteString("<div class=\"alert alert-warning\" role=\"status\" hx-post=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString(url))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\" hx-trigger=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString("load delay:" + strconv.Itoa(retryAfter) + "s"))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\" hx-target=\"this\" hx-swap=\"outerHTML\">")
+			if err != nil {
+				return err
+			}
+			var_63 := `Too many requests.  Trying again in `
+			_, err = templBuffer.WriteString(var_63)
+			if err != nil {
+				return err
+			}
+			var var_64 string = strconv.Itoa(retryAfter)
+			_, err = templBuffer.WriteString(templ.EscapeString(var_64))
+			if err != nil {
+				return err
+			}
+			var_65 := `s.`
+			_, err = templBuffer.WriteString(var_65)
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</div>")
+			if err != nil {
+				return err
+			}
+		} else {
+			_, err = templBuffer.WriteString("<div class=\"alert alert-warning\" role=\"status\" hx-get=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString(url))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\" hx-trigger=\"")
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString(templ.EscapeString("load delay:" + strconv.Itoa(retryAfter) + "s"))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\" hx-target=\"this\" hx-swap=\"outerHTML\">")
+			if err != nil {
+				return err
+			}
+			var_66 := `Too many requests.  Trying again in `
+			_, err = templBuffer.WriteString(var_66)
+			if err != nil {
+				return err
+			}
+			var var_67 string = strconv.Itoa(retryAfter)
+			_, err = templBuffer.WriteString(templ.EscapeString(var_67))
+			if err != nil {
+				return err
+			}
+			var_68 := `s.`
+			_, err = templBuffer.WriteString(var_68)
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</div>")
+			if err != nil {
+				return err
+			}
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func loginPage(form server.AccountForm) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_69 := templ.GetChildren(ctx)
+		if var_69 == nil {
+			var_69 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, err = templBuffer.WriteString("<h1>")
+		if err != nil {
+			return err
+		}
+		var_70 := `Log in`
+		_, err = templBuffer.WriteString(var_70)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</h1><form method=\"post\" action=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.URLFor(server.RouteLogin)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" hx-boost=\"true\" hx-target=\"#main-layout\" class=\"col-md-4\">")
+		if err != nil {
+			return err
+		}
+		if form.Error != "" {
+			_, err = templBuffer.WriteString("<div class=\"alert alert-danger\" role=\"alert\">")
+			if err != nil {
+				return err
+			}
+			var var_71 string = form.Error
+			_, err = templBuffer.WriteString(templ.EscapeString(var_71))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</div>")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("<input type=\"hidden\" name=\"next\" value=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(form.Next))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\"><div class=\"mb-3\"><label for=\"username\" class=\"form-label\">")
+		if err != nil {
+			return err
+		}
+		var_72 := `User name`
+		_, err = templBuffer.WriteString(var_72)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</label><input type=\"text\" class=\"form-control\" id=\"username\" name=\"username\" value=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(form.Username))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" autocomplete=\"username\" required></div><div class=\"mb-3\"><label for=\"password\" class=\"form-label\">")
+		if err != nil {
+			return err
+		}
+		var_73 := `Password`
+		_, err = templBuffer.WriteString(var_73)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</label><input type=\"password\" class=\"form-control\" id=\"password\" name=\"password\" autocomplete=\"current-password\" required></div><input type=\"submit\" class=\"btn btn-primary\" value=\"Log in\"><a href=\"")
+		if err != nil {
+			return err
+		}
+		var var_74 templ.SafeURL = templ.SafeURL(server.URLFor(server.RouteRegister, "next", form.Next))
+		_, err = templBuffer.WriteString(templ.EscapeString(string(var_74)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" class=\"ms-3\">")
+		if err != nil {
+			return err
+		}
+		var_75 := `Register`
+		_, err = templBuffer.WriteString(var_75)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</a></form>")
+		if err != nil {
+			return err
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func registerPage(form server.AccountForm) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_76 := templ.GetChildren(ctx)
+		if var_76 == nil {
+			var_76 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, err = templBuffer.WriteString("<h1>")
+		if err != nil {
+			return err
+		}
+		var_77 := `Register`
+		_, err = templBuffer.WriteString(var_77)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</h1><form method=\"post\" action=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(server.URLFor(server.RouteRegister)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" hx-boost=\"true\" hx-target=\"#main-layout\" class=\"col-md-4\">")
+		if err != nil {
+			return err
+		}
+		if form.Error != "" {
+			_, err = templBuffer.WriteString("<div class=\"alert alert-danger\" role=\"alert\">")
+			if err != nil {
+				return err
+			}
+			var var_78 string = form.Error
+			_, err = templBuffer.WriteString(templ.EscapeString(var_78))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</div>")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("<input type=\"hidden\" name=\"next\" value=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(form.Next))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\"><div class=\"mb-3\"><label for=\"username\" class=\"form-label\">")
+		if err != nil {
+			return err
+		}
+		var_79 := `User name`
+		_, err = templBuffer.WriteString(var_79)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</label><input type=\"text\" class=\"form-control\" id=\"username\" name=\"username\" value=\"")
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString(templ.EscapeString(form.Username))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" autocomplete=\"username\" required></div><div class=\"mb-3\"><label for=\"password\" class=\"form-label\">")
+		if err != nil {
+			return err
+		}
+		var_80 := `Password`
+		_, err = templBuffer.WriteString(var_80)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</label><input type=\"password\" class=\"form-control\" id=\"password\" name=\"password\" autocomplete=\"new-password\" minlength=\"8\" required></div><div class=\"mb-3\"><label for=\"confirm\" class=\"form-label\">")
+		if err != nil {
+			return err
+		}
+		var_81 := `Confirm password`
+		_, err = templBuffer.WriteString(var_81)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</label><input type=\"password\" class=\"form-control\" id=\"confirm\" name=\"confirm\" autocomplete=\"new-password\" minlength=\"8\" required></div><input type=\"submit\" class=\"btn btn-primary\" value=\"Register\"><a href=\"")
+		if err != nil {
+			return err
+		}
+		var var_82 templ.SafeURL = templ.SafeURL(server.URLFor(server.RouteLogin, "next", form.Next))
+		_, err = templBuffer.WriteString(templ.EscapeString(string(var_82)))
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("\" class=\"ms-3\">")
+		if err != nil {
+			return err
+		}
+		var_83 := `Log in`
+		_, err = templBuffer.WriteString(var_83)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</a></form>")
+		if err != nil {
+			return err
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func adminPage(pages []server.Route) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_84 := templ.GetChildren(ctx)
+		if var_84 == nil {
+			var_84 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, err = templBuffer.WriteString("<h1>")
+		if err != nil {
+			return err
+		}
+		var_85 := `Admin`
+		_, err = templBuffer.WriteString(var_85)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</h1><ul>")
+		if err != nil {
+			return err
+		}
+		for _, page := range pages {
+			_, err = templBuffer.WriteString("<li><a href=\"")
+			if err != nil {
+				return err
+			}
+			var var_86 templ.SafeURL = templ.SafeURL(page.Path)
+			_, err = templBuffer.WriteString(templ.EscapeString(string(var_86)))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("\">")
+			if err != nil {
+				return err
+			}
+			var var_87 string = page.Title
+			_, err = templBuffer.WriteString(templ.EscapeString(var_87))
+			if err != nil {
+				return err
+			}
+			_, err = templBuffer.WriteString("</a></li>")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = templBuffer.WriteString("</ul>")
+		if err != nil {
+			return err
+		}
+		if !templIsBuffer {
+			_, err = templBuffer.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func usersPage(users []*server.User) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		templBuffer, templIsBuffer := w.(*bytes.Buffer)
+		if !templIsBuffer {
+			templBuffer = templ.GetBuffer()
+			defer templ.ReleaseBuffer(templBuffer)
+		}
+		ctx = templ.InitializeContext(ctx)
+		var_88 := templ.GetChildren(ctx)
+		if var_88 == nil {
+			var_88 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		_, err = templBuffer.WriteString("<h1>")
+		if err != nil {
+			return err
+		}
+		var_89 := `Users`
+		_, err = templBuffer.WriteString(var_89)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</h1><table class=\"table\"><thead><tr><th>")
+		if err != nil {
+			return err
+		}
+		var_90 := `Name`
+		_, err = templBuffer.WriteString(var_90)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</th><th>")
+		if err != nil {
+			return err
+		}
+		var_91 := `Roles`
+		_, err = templBuffer.WriteString(var_91)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</th><th>")
+		if err != nil {
+			return err
+		}
+		var_92 := `Registered`
+		_, err = templBuffer.WriteString(var_92)
+		if err != nil {
+			return err
+		}
+		_, err = templBuffer.WriteString("</th></tr></thead><tbody>")
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			_, err = templBuffer.WriteString("<tr><td>")
+			if err != nil {
+				return err
+			}
+			var var_93 string = user.Name
+			_, err = templBuffer.WriteString(templ.EscapeString(var_93))
 			if err != nil {
 				return err
 			}
@@ -667,8 +1549,8 @@ func forecasts(forecasts []Forecast) templ.Component {
 			if err != nil {
 				return err
 			}
-			var var_44 string = strconv.Itoa(forecast.TemperatureF)
-			_, err = templBuffer.WriteString(templ.EscapeString(var_44))
+			var var_94 string = user.RoleList()
+			_, err = templBuffer.WriteString(templ.EscapeString(var_94))
 			if err != nil {
 				return err
 			}
@@ -676,8 +1558,8 @@ func forecasts(forecasts []Forecast) templ.Component {
 			if err != nil {
 				return err
 			}
-			var var_45 string = forecast.Summary
-			_, err = templBuffer.WriteString(templ.EscapeString(var_45))
+			var var_95 string = user.Created.Format("1/2/2006")
+			_, err = templBuffer.WriteString(templ.EscapeString(var_95))
 			if err != nil {
 				return err
 			}
@@ -1,8 +1,8 @@
 #!/usr/bin/bash
 #
 # Automatically reruns the app whenever a go source file our template
-# source file changes.
+# source file changes, here or in the server it shares with GoApp.
 #
 # Requires https://github.com/watchexec/watchexec
 
-watchexec -e templ -- templ generate & watchexec -r -e go -- go run . &
+watchexec -e templ -- templ generate & watchexec -r -e go -w . -w ../GoServer -- go run . &
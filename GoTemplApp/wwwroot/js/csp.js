@@ -0,0 +1,7 @@
+// Send this page's CSP nonce with htmx requests, so the inline styles and
+// scripts in the fragments that come back are allowed to run here.
+document.body.addEventListener("htmx:configRequest", function (evt) {
+    if (htmx.config.inlineScriptNonce) {
+        evt.detail.headers["X-CSP-Nonce"] = htmx.config.inlineScriptNonce;
+    }
+});
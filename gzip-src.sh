@@ -8,6 +8,7 @@ gzipDotNet BlazorApp
 gzipDotNet RazorApp
 gzipDotNet GiraffeApp
 
+tar -czvf GoServer-src.tar.gz $(find GoServer -name "*.go")
 tar -czvf GoApp-src.tar.gz GoApp/*.go GoApp/templates/*
 tar -czvf GoTemplApp-src.tar.gz $(
     find GoTemplApp -name "*.go" -o -name "*.templ" | grep -v _templ.go)